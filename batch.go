@@ -0,0 +1,88 @@
+package coz
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// VerifyItem is a coz and the key to verify it with for VerifyBatch.
+type VerifyItem struct {
+	Coz *Coz
+	Key *Key
+}
+
+// VerifyResult is the result of verifying a VerifyItem.  Err is set on error,
+// including cancellation, in which case Valid is false.
+type VerifyResult struct {
+	Valid bool
+	Err   error
+}
+
+// VerifyBatch verifies items concurrently using a pool of workers and returns
+// results in the same order as items.  If workers is less than 1,
+// runtime.GOMAXPROCS(0) is used.  When ctx is done, unverified items have Err
+// set to ctx.Err().
+//
+// Unlike VerifyCoz, which unmarshals and compacts `pay` separately from Meta,
+// VerifyBatch calls MetaWithAlg once per coz, on a copy, and verifies `sig`
+// over the resulting `cad`.  Items are not modified.  Provided `can`, `cad`, and
+// `czd` are checked as by Check, and a mismatch is reported as a
+// *MismatchError.
+func VerifyBatch(ctx context.Context, items []VerifyItem, workers int) []VerifyResult {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(items))
+	results := make([]VerifyResult, len(items))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				results[i].Valid, results[i].Err = items[i].Key.verifyMeta(items[i].Coz)
+			}
+		}()
+	}
+
+	for i := range items {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// verifyMeta is VerifyCoz using MetaWithAlg on a copy of cz.  Provided meta
+// fields must match, and `sig` is verified over `cad`.
+func (c *Key) verifyMeta(cz *Coz) (bool, error) {
+	if c == nil || cz == nil {
+		return false, fmt.Errorf("VerifyBatch: coz and key must be set")
+	}
+	m := &Coz{Pay: cz.Pay, Sig: cz.Sig, Parsed: new(Pay)}
+	err := m.MetaWithAlg(c.Alg) // Errors on mismatched `pay.alg`.
+	if err != nil {
+		return false, err
+	}
+	if len(m.Parsed.Tmb) != 0 && !bytes.Equal(c.Tmb, m.Parsed.Tmb) {
+		return false, fmt.Errorf("VerifyBatch: key tmb %q and coz tmb %q do not match", c.Tmb, m.Parsed.Tmb)
+	}
+	err = cz.mismatch(m)
+	if err != nil {
+		return false, err
+	}
+	return c.Verify(m.Cad, m.Sig), nil
+}
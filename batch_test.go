@@ -0,0 +1,74 @@
+package coz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func ExampleVerifyBatch() {
+	cz := new(Coz)
+	err := json.Unmarshal([]byte(GoldenCoz), cz)
+	if err != nil {
+		panic(err)
+	}
+	bad := &Coz{Pay: cz.Pay, Sig: MustDecode(GoldenSig)}
+	bad.Sig[0] ^= 1
+	noAlg := new(Coz)
+	err = json.Unmarshal(GoldenCozNoAlg, noAlg)
+	if err != nil {
+		panic(err)
+	}
+
+	wrongCzd := &Coz{Pay: cz.Pay, Sig: cz.Sig, Czd: MustDecode(GoldenCad)}
+
+	items := []VerifyItem{
+		{Coz: cz, Key: &GoldenKey},
+		{Coz: bad, Key: &GoldenKey},
+		{Coz: noAlg, Key: &GoldenKey}, // Contextual coz.
+		{Coz: wrongCzd, Key: &GoldenKey},
+	}
+	for _, r := range VerifyBatch(context.Background(), items, 2) {
+		fmt.Println(r.Valid, r.Err)
+	}
+	fmt.Println(cz.Czd == nil) // Items are not modified.
+
+	// Output:
+	// true <nil>
+	// false <nil>
+	// true <nil>
+	// false Coz: czd mismatch; expected xrYMu87EXes58PnEACcDW1t0jF2ez4FCN-njTF0MHNo, given XzrXMGnY0QFwAKkr43Hh-Ku3yUS8NVE0BdzSlMLSuTU
+	// true
+}
+
+func TestVerifyBatch_cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	items := make([]VerifyItem, 10)
+	for i := range items {
+		items[i] = VerifyItem{Coz: &Coz{Pay: []byte(GoldenPay), Sig: MustDecode(GoldenSig)}, Key: &GoldenKey}
+	}
+	for i, r := range VerifyBatch(ctx, items, 4) {
+		if r.Err != context.Canceled || r.Valid {
+			t.Fatalf("item %d: expected context.Canceled; given %v %v", i, r.Valid, r.Err)
+		}
+	}
+}
+
+// batchItems returns n signed items for benchmarks.
+func batchItems(b *testing.B, alg SigAlg, n int) []VerifyItem {
+	k, err := NewSigningKey(alg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	items := make([]VerifyItem, n)
+	for i := range items {
+		cz, err := k.SignPay(&Pay{Alg: k.Alg, Tmb: k.Tmb, Now: 1, Typ: fmt.Sprint(i)})
+		if err != nil {
+			b.Fatal(err)
+		}
+		items[i] = VerifyItem{Coz: cz, Key: k}
+	}
+	return items
+}
@@ -0,0 +1,141 @@
+package coz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// TildeField is the `pay` field encapsulating application JSON, kept separate
+// from standard fields.  When present, `~` is the last field.
+const TildeField = "~"
+
+// PayBuilder builds a `pay` with field order controlled by the caller.  Fields
+// are emitted in the order added, followed by `~` if set, and the resulting
+// `can` is exactly that order.  The zero value is not usable; use
+// NewPayBuilder.
+//
+// Errors, such as a duplicate field, are deferred until JSON or Sign.
+type PayBuilder struct {
+	m     *Object
+	tilde any
+	err   error
+}
+
+// NewPayBuilder returns an empty PayBuilder.
+func NewPayBuilder() *PayBuilder {
+	return &PayBuilder{m: NewObject()}
+}
+
+// Add appends the field key with value.  Value is marshaled with Marshal.
+// Adding a duplicate field or `~` is an error.  See Tilde.
+func (b *PayBuilder) Add(key string, value any) *PayBuilder {
+	if b.err != nil {
+		return b
+	}
+	if key == TildeField {
+		b.err = errors.New("PayBuilder: use Tilde for field \"~\"")
+		return b
+	}
+	if _, ok := b.m.Get(key); ok {
+		b.err = fmt.Errorf("PayBuilder: duplicate field %q", key)
+		return b
+	}
+	v, err := Marshal(value)
+	if err != nil {
+		b.err = fmt.Errorf("PayBuilder: field %q: %w", key, err)
+		return b
+	}
+	b.m.Set(key, json.RawMessage(v))
+	return b
+}
+
+// Std appends the non-zero standard fields of p in the order alg, now, tmb,
+// typ, rvk.  If p has `~` set, it is set as the builder's `~`.  p.Struct is
+// ignored.
+func (b *PayBuilder) Std(p *Pay) *PayBuilder {
+	if p.Alg != "" {
+		b.Add("alg", p.Alg)
+	}
+	if p.Now != 0 {
+		b.Add("now", p.Now)
+	}
+	if len(p.Tmb) != 0 {
+		b.Add("tmb", p.Tmb)
+	}
+	if p.Typ != "" {
+		b.Add("typ", p.Typ)
+	}
+	if p.Rvk != 0 {
+		b.Add("rvk", p.Rvk)
+	}
+	if p.tilde != nil {
+		b.Tilde(p.tilde)
+	}
+	return b
+}
+
+// Tilde sets the tilde encapsulated sub-object, emitted as the last field
+// `~`.  v must marshal to a JSON object.
+func (b *PayBuilder) Tilde(v any) *PayBuilder {
+	b.tilde = v
+	return b
+}
+
+// Can returns the `can` of the built `pay`.
+func (b *PayBuilder) Can() []string {
+	can := slices.Clone(b.m.Keys())
+	if b.tilde != nil {
+		can = append(can, TildeField)
+	}
+	return can
+}
+
+// JSON returns the compact `pay`.
+func (b *PayBuilder) JSON() (json.RawMessage, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	m := b.m
+	if b.tilde != nil {
+		t, err := Marshal(b.tilde)
+		if err != nil {
+			return nil, fmt.Errorf("PayBuilder: field %q: %w", TildeField, err)
+		}
+		if len(t) == 0 || t[0] != '{' {
+			return nil, fmt.Errorf("PayBuilder: field %q must be a JSON object", TildeField)
+		}
+		m = &Object{keys: slices.Clone(b.m.keys), values: maps.Clone(b.m.values)}
+		m.Set(TildeField, json.RawMessage(t))
+	}
+	pay, err := Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	// Guarantee that `can` is exactly the requested order.
+	can, err := Canon(pay)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Equal(can, b.Can()) {
+		return nil, fmt.Errorf("PayBuilder: can %q does not match requested %q", can, b.Can())
+	}
+	return pay, nil
+}
+
+// Sign signs the built `pay` with key without reordering or modifying any
+// field.  If present, `alg` and `tmb` must match key.
+func (b *PayBuilder) Sign(key *Key) (*Coz, error) {
+	pay, err := b.JSON()
+	if err != nil {
+		return nil, err
+	}
+	p := new(Pay)
+	err = p.unmarshal(pay, Limits{})
+	if err != nil {
+		return nil, err
+	}
+	return key.signPayJSON(p, pay)
+}
@@ -0,0 +1,76 @@
+package coz
+
+import (
+	"fmt"
+	"testing"
+)
+
+func ExamplePayBuilder() {
+	b := NewPayBuilder().
+		Add("msg", "Coz is a cryptographic JSON messaging specification.").
+		Std(&Pay{Alg: GoldenKey.Alg, Now: 1623132000, Tmb: GoldenKey.Tmb, Typ: "cyphr.me/msg/create"}).
+		Tilde(map[string]any{"app": "<&>"})
+	pay, err := b.JSON()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(pay))
+	fmt.Println(b.Can())
+
+	cz, err := b.Sign(&GoldenKey)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(GoldenKey.VerifyCoz(cz))
+	fmt.Println(string(cz.Pay) == string(pay))
+
+	// Output:
+	// {"msg":"Coz is a cryptographic JSON messaging specification.","alg":"ES256","now":1623132000,"tmb":"U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg","typ":"cyphr.me/msg/create","~":{"app":"<&>"}}
+	// [msg alg now tmb typ ~]
+	// true <nil>
+	// true
+}
+
+func TestPayBuilder(t *testing.T) {
+	// The golden `pay` is reproduced exactly, including `cad`.
+	b := NewPayBuilder().
+		Add("msg", "Coz is a cryptographic JSON messaging specification.").
+		Std(&Pay{Alg: GoldenKey.Alg, Now: 1623132000, Tmb: GoldenKey.Tmb, Typ: "cyphr.me/msg/create"})
+	pay, err := b.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cad, err := Hash(SHA256, pay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cad.String() != GoldenCad {
+		t.Fatalf("expected cad %s, given %s", GoldenCad, cad)
+	}
+
+	for name, b := range map[string]*PayBuilder{
+		"duplicate":        NewPayBuilder().Add("a", 1).Add("a", 2),
+		"tilde via Add":    NewPayBuilder().Add(TildeField, map[string]int{}),
+		"tilde not object": NewPayBuilder().Tilde([]int{1}),
+		"unmarshalable":    NewPayBuilder().Add("c", make(chan int)),
+	} {
+		if _, err := b.JSON(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	// Signing does not update `now`.
+	cz, err := NewPayBuilder().Std(&Pay{Alg: GoldenKey.Alg, Now: 1}).Sign(&GoldenKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(cz.Pay) != `{"alg":"ES256","now":1}` {
+		t.Fatalf("unexpected pay %s", cz.Pay)
+	}
+
+	// Mismatched key.
+	_, err = NewPayBuilder().Std(&Pay{Alg: SEAlg(ES384)}).Sign(&GoldenKey)
+	if err == nil {
+		t.Fatal("expected alg mismatch error")
+	}
+}
@@ -3,14 +3,29 @@ package coz
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 )
 
 // Canon returns the current canon from raw JSON.
 //
 // It returns only top level fields with no recursion or promotion of embedded
-// fields.
+// fields.  No limits are applied.  For untrusted input, see CanonLimits.
 func Canon(raw json.RawMessage) (can []string, err error) {
-	o := newOrderedMap()
+	return canon(raw, Limits{})
+}
+
+// CanonLimits is Canon with the structural limits of l (MaxDepth, MaxFields,
+// and MaxStringLen).  Size limits are not applied since raw may be any JSON
+// object.
+func CanonLimits(raw json.RawMessage, l Limits) (can []string, err error) {
+	return canon(raw, l)
+}
+
+// canonV1 is CanonLimits using encoding/json and Object.
+func canonV1(raw json.RawMessage, l Limits) (can []string, err error) {
+	o := NewObject()
+	o.Limits = &l
 	err = json.Unmarshal(raw, &o)
 	if err != nil {
 		return nil, err
@@ -21,9 +36,10 @@ func Canon(raw json.RawMessage) (can []string, err error) {
 // Canonical returns the canonical form. Input canon is optional and may be nil.
 // If canon is nil, input JSON is only compactified.
 //
-// Interface "canon" may be `[]string`, `struct“, or `nil`.  If "canon" is a
-// struct or slice it must be properly ordered.  If canon is nil, json.Unmarshal
-// will place the input into a UTF-8 ordered map.
+// Interface "canon" may be `[]string`, `struct“, `*CanonSpec`, or `nil`.  If
+// "canon" is a struct or slice it must be properly ordered.  If canon is nil,
+// json.Unmarshal will place the input into a UTF-8 ordered map.  For
+// *CanonSpec, see CanonicalSpec.
 //
 // In the Go version of Coz, the canonical form of a struct is (currently)
 // achieved by unmarshalling and remarshalling.
@@ -31,6 +47,9 @@ func Canonical(input []byte, canon any) (b []byte, err error) {
 	if canon == nil {
 		return compact(input)
 	}
+	if spec, ok := canon.(*CanonSpec); ok {
+		return CanonicalSpec(input, spec)
+	}
 
 	s, ok := canon.([]string)
 	if ok {
@@ -61,6 +80,102 @@ func Canonical(input []byte, canon any) (b []byte, err error) {
 	return Marshal(canon)
 }
 
+// CanonSpec is a canon tree for CanonicalSpec.  Can is the canon of an object,
+// and Sub is the canon of the value of a field, which must be an object, an
+// array, or null.  A nil Can keeps every field in input order.  Fields without
+// a Sub are compactified but otherwise unmodified.
+//
+// For example, KeyCanon as a spec is &CanonSpec{Can: KeyCanon}.
+type CanonSpec struct {
+	Can []string
+	Sub map[string]*CanonSpec
+}
+
+// CanonicalSpec returns the canonical form of input with fields filtered and
+// ordered by spec at every level.  A spec applied to an array is applied to
+// each element, so arrays of objects, like the cozies of a multisig envelope,
+// share one spec.  Fields in Can missing from the input are omitted.  If spec
+// is nil, input JSON is only compactified.
+//
+// Unlike Canonical, values are never unmarshalled and remarshalled, so numbers
+// and strings keep their input form.
+func CanonicalSpec(input []byte, spec *CanonSpec) (b []byte, err error) {
+	if spec == nil {
+		return compact(input)
+	}
+	return spec.canonical(input)
+}
+
+func (s *CanonSpec) canonical(input json.RawMessage) ([]byte, error) {
+	input = bytes.TrimLeft(input, " \t\r\n")
+	if len(input) == 0 {
+		return nil, errors.New("Coz: CanonicalSpec: empty input")
+	}
+	switch input[0] {
+	case 'n':
+		return compact(input)
+	case '[':
+		var a []json.RawMessage
+		err := json.Unmarshal(input, &a)
+		if err != nil {
+			return nil, err
+		}
+		b := []byte{'['}
+		for i, e := range a {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			v, err := s.canonical(e)
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, v...)
+		}
+		return append(b, ']'), nil
+	case '{':
+	default:
+		return nil, fmt.Errorf("Coz: CanonicalSpec: value is not an object or array: %.20s", input)
+	}
+
+	can, err := Canon(input) // Errors on duplicates.
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]json.RawMessage)
+	err = json.Unmarshal(input, &m)
+	if err != nil {
+		return nil, err
+	}
+	if s.Can != nil {
+		can = s.Can
+	}
+
+	b := []byte{'{'}
+	for _, f := range can {
+		v, ok := m[f]
+		if !ok {
+			continue
+		}
+		if len(b) > 1 {
+			b = append(b, ',')
+		}
+		name, err := Marshal(f)
+		if err != nil {
+			return nil, err
+		}
+		if sub := s.Sub[f]; sub != nil {
+			v, err = sub.canonical(v)
+		} else {
+			v, err = compact(v)
+		}
+		if err != nil {
+			return nil, err
+		}
+		b = append(append(append(b, name...), ':'), v...)
+	}
+	return append(b, '}'), nil
+}
+
 // CanonicalHash accepts []byte and optional canon and returns digest.
 //
 // If input is already in canonical form, Hash() may also be called instead.
@@ -72,8 +187,8 @@ func CanonicalHash(input []byte, canon any, hash HshAlg) (digest B64, err error)
 	return Hash(hash, input)
 }
 
-// Compact is a helper that compactifies JSON.
-func compact(msg json.RawMessage) ([]byte, error) {
+// compactV1 is compact using encoding/json.
+func compactV1(msg json.RawMessage) ([]byte, error) {
 	var b bytes.Buffer
 	err := json.Compact(&b, msg)
 	if err != nil {
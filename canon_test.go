@@ -2,6 +2,7 @@ package coz
 
 import (
 	"fmt"
+	"testing"
 )
 
 func ExampleCanon() {
@@ -152,3 +153,80 @@ func ExampleCanonical_slice() {
 
 	// Output: U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg
 }
+
+// ExampleCanonicalSpec digests a multisig envelope, reducing each embedded key
+// to KeyCanon.
+func ExampleCanonicalSpec() {
+	envelope := []byte(`{
+		"typ": "cyphr.me/multisig",
+		"note": "dropped",
+		"cozies": [
+			{"sig": "AA", "pay": {"typ": "a", "alg": "ES256"}, "key": {"tag": "dropped", "pub": "AQ", "alg": "ES256"}},
+			{"pay": {"alg": "ES256", "typ": "b"}, "sig": "Ag"}
+		]
+	}`)
+	spec := &CanonSpec{
+		Can: []string{"typ", "cozies"},
+		Sub: map[string]*CanonSpec{"cozies": {
+			Can: []string{"pay", "key", "sig"},
+			Sub: map[string]*CanonSpec{
+				"pay": {Can: []string{"alg", "typ"}},
+				"key": {Can: KeyCanon},
+			},
+		}},
+	}
+	b, err := CanonicalSpec(envelope, spec)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", b)
+
+	dig, err := CanonicalHash(envelope, spec, SHA256)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(len(dig))
+
+	// Output:
+	// {"typ":"cyphr.me/multisig","cozies":[{"pay":{"alg":"ES256","typ":"a"},"key":{"alg":"ES256","pub":"AQ"},"sig":"AA"},{"pay":{"alg":"ES256","typ":"b"},"sig":"Ag"}]}
+	// 32
+}
+
+func TestCanonicalSpec(t *testing.T) {
+	tests := []struct {
+		in   string
+		spec *CanonSpec
+		want string
+	}{
+		{`{"b":1, "a":[1.0, 2]}`, nil, `{"b":1,"a":[1.0,2]}`},
+		{`{"b":1, "a":1e3}`, &CanonSpec{}, `{"b":1,"a":1e3}`}, // Number form is kept.
+		{`{"b":1, "a":2}`, &CanonSpec{Can: []string{"a", "c"}}, `{"a":2}`},
+		{`{"b":1, "a":2}`, &CanonSpec{Can: []string{}}, `{}`},
+		{`[{"b":1,"a":2},{"a":3}]`, &CanonSpec{Can: []string{"a", "b"}}, `[{"a":2,"b":1},{"a":3}]`},
+		{`{"x":[[{"b":1,"a":2}],null]}`, &CanonSpec{Sub: map[string]*CanonSpec{"x": {Can: []string{"a"}}}}, `{"x":[[{"a":2}],null]}`},
+		{`{"x":null}`, &CanonSpec{Sub: map[string]*CanonSpec{"x": {}}}, `{"x":null}`},
+		{`{"x":{"<&>":"<&>"}}`, &CanonSpec{Sub: map[string]*CanonSpec{"x": {}}}, `{"x":{"<&>":"<&>"}}`},
+	}
+	for _, tt := range tests {
+		b, err := CanonicalSpec([]byte(tt.in), tt.spec)
+		if err != nil {
+			t.Errorf("%s: %v", tt.in, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("%s: given %s, want %s", tt.in, b, tt.want)
+		}
+	}
+
+	for _, in := range []string{
+		`{"x":1}`,               // Sub on a scalar.
+		`{"x":{"a":1,"a":2}}`,   // Duplicate in a nested object.
+		`{"x":[{"a":1,"a":2}]}`, // Duplicate in an array of objects.
+		`{"x":`,
+	} {
+		_, err := CanonicalSpec([]byte(in), &CanonSpec{Sub: map[string]*CanonSpec{"x": {}}})
+		if err == nil {
+			t.Errorf("%s: expected error", in)
+		}
+	}
+}
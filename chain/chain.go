@@ -0,0 +1,247 @@
+/*
+Package chain implements a tamper-evident, hash-chained, append-only log of
+cozies.  Each entry's `pay` includes the previous entry's `czd` in the field
+`prv_czd`, so modifying, removing, or reordering any entry breaks every
+subsequent link.  The first entry has no `prv_czd`.
+
+Logs are stored as newline delimited cozies.  See coz.Decoder.
+*/
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/cyphrme/coz"
+)
+
+// LinkField is the `pay` field holding the previous entry's `czd`.
+const LinkField = "prv_czd"
+
+// ChainError reports the first broken entry of a chain.  Index is 0-based.
+type ChainError struct {
+	Index int
+	Err   error
+}
+
+// Error implements error.
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("chain: entry %d: %s", e.Index, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ChainError) Unwrap() error {
+	return e.Err
+}
+
+// link is the custom `pay` struct for reading LinkField.
+type link struct {
+	PrvCzd coz.B64 `json:"prv_czd,omitempty"`
+}
+
+// Log is an append-only, hash-chained log persisted to a local file.  Log is
+// safe for concurrent use.
+type Log struct {
+	mu   sync.Mutex
+	f    *os.File
+	prev coz.B64       // `czd` of the last entry.
+	now  coz.Timestamp // `now` of the last entry.
+	n    int
+}
+
+// Open opens or creates the log file name.  Existing entries are read to
+// recover the last `czd` and `now`; their links are checked but signatures
+// are not verified.  Use VerifyChain to fully verify a log.
+func Open(name string) (*Log, error) {
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{f: f}
+	err = walk(f, func(i int, cz *coz.Coz, prv coz.B64) error {
+		if !bytes.Equal(prv, l.prev) {
+			return errors.New("broken link")
+		}
+		if cz.Parsed.Now < l.now {
+			return errors.New("now is not monotonic")
+		}
+		l.prev, l.now, l.n = cz.Czd, cz.Parsed.Now, i+1
+		return nil
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Len returns the number of entries.
+func (l *Log) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.n
+}
+
+// Head returns the `czd` of the last entry, or nil if the log is empty.
+func (l *Log) Head() coz.B64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.prev
+}
+
+// Append signs pay with key, setting the link to the previous entry, and
+// appends the resulting coz to the log.  `pay.alg` and `pay.tmb` are set from
+// key if empty.  If `pay.now` is zero it is set to the current time.  `now`
+// must not be earlier than the previous entry's `now`.  pay must not already
+// contain LinkField.
+func (l *Log) Append(key *coz.Key, pay *coz.Pay) (*coz.Coz, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil, errors.New("chain: log is closed")
+	}
+
+	p := *pay
+	if p.Alg == "" {
+		p.Alg = key.Alg
+	}
+	if len(p.Tmb) == 0 {
+		p.Tmb = key.Tmb
+	}
+	if p.Now == 0 {
+		p.Now = coz.Now()
+	}
+	if p.Now < l.now {
+		return nil, fmt.Errorf("chain: now %d is earlier than previous entry now %d", p.Now, l.now)
+	}
+
+	if p.Alg != key.Alg || !bytes.Equal(p.Tmb, key.Tmb) {
+		return nil, errors.New("chain: pay alg and tmb must match key")
+	}
+
+	b, err := withLink(&p, l.prev)
+	if err != nil {
+		return nil, err
+	}
+	// Sign b directly.  Key.SignPayJSON would remarshal `pay` when `now` is
+	// set, dropping the link.
+	cad, err := coz.Hash(key.Alg.Hash(), b)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := key.Sign(cad)
+	if err != nil {
+		return nil, err
+	}
+	cz := &coz.Coz{Pay: b, Sig: sig}
+	err = cz.Meta()
+	if err != nil {
+		return nil, err
+	}
+
+	err = coz.NewEncoder(l.f).Encode(&coz.Coz{Pay: cz.Pay, Sig: cz.Sig})
+	if err != nil {
+		return nil, err
+	}
+	if err = l.f.Sync(); err != nil {
+		return nil, err
+	}
+	l.prev, l.now, l.n = cz.Czd, cz.Parsed.Now, l.n+1
+	return cz, nil
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+// withLink marshals p and appends LinkField with prev.  If prev is nil,
+// LinkField is omitted.
+func withLink(p *coz.Pay, prev coz.B64) ([]byte, error) {
+	b, err := coz.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	if _, ok := fields[LinkField]; ok {
+		return nil, fmt.Errorf("chain: pay must not contain %q", LinkField)
+	}
+	if prev == nil {
+		return b, nil
+	}
+	b = b[:len(b)-1] // Remove trailing '}'
+	if len(fields) > 0 {
+		b = append(b, ',')
+	}
+	return fmt.Appendf(b, "%q:%q}", LinkField, prev), nil
+}
+
+// walk decodes entries from r, calls Meta, and calls fn with each entry and
+// its LinkField value.  Errors are returned as *ChainError.
+func walk(r io.Reader, fn func(i int, cz *coz.Coz, prv coz.B64) error) error {
+	dec := coz.NewDecoder(r)
+	for i := 0; ; i++ {
+		cz, err := dec.Decode()
+		if err == io.EOF {
+			return nil
+		}
+		if err == nil {
+			err = cz.Meta()
+		}
+		var lk link
+		if err == nil {
+			err = json.Unmarshal(cz.Pay, &coz.Pay{Struct: &lk})
+		}
+		if err == nil {
+			err = fn(i, cz, lk.PrvCzd)
+		}
+		if err != nil {
+			return &ChainError{Index: i, Err: err}
+		}
+	}
+}
+
+// VerifyChain verifies a log read from r.  Every entry's signature is verified
+// with the key in keys for `pay.tmb`, every link is checked against the
+// previous entry's `czd`, and `now` must be non-decreasing.  VerifyChain
+// returns a *ChainError for the first broken entry.
+func VerifyChain(r io.Reader, keys map[coz.B64s]*coz.Key) error {
+	var prev coz.B64
+	var now coz.Timestamp
+	return walk(r, func(i int, cz *coz.Coz, prv coz.B64) error {
+		k, ok := keys[coz.B64s(cz.Parsed.Tmb)]
+		if !ok {
+			return fmt.Errorf("unknown key tmb %s", cz.Parsed.Tmb)
+		}
+		v, err := k.VerifyCoz(cz)
+		if err != nil {
+			return err
+		}
+		if !v {
+			return errors.New("invalid signature")
+		}
+		if !bytes.Equal(prv, prev) {
+			return fmt.Errorf("broken link; expected %s %q, given %q", LinkField, prev, prv)
+		}
+		if cz.Parsed.Now < now {
+			return fmt.Errorf("now %d is earlier than previous entry now %d", cz.Parsed.Now, now)
+		}
+		prev, now = cz.Czd, cz.Parsed.Now
+		return nil
+	})
+}
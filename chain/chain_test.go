@@ -0,0 +1,123 @@
+package chain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cyphrme/coz"
+)
+
+type msg struct {
+	Msg string `json:"msg"`
+}
+
+func ExampleLog_Append() {
+	name := filepath.Join(os.TempDir(), "coz_chain_example.ndjson")
+	os.Remove(name)
+	defer os.Remove(name)
+
+	k, err := coz.NewKey(coz.SEAlg(coz.ES256))
+	if err != nil {
+		panic(err)
+	}
+	l, err := Open(name)
+	if err != nil {
+		panic(err)
+	}
+	first, err := l.Append(k, &coz.Pay{Typ: "example/msg", Struct: &msg{"first"}})
+	if err != nil {
+		panic(err)
+	}
+	second, err := l.Append(k, &coz.Pay{Typ: "example/msg", Struct: &msg{"second"}})
+	if err != nil {
+		panic(err)
+	}
+	l.Close()
+	fmt.Println(first.Can)
+	fmt.Println(second.Can)
+
+	f, err := os.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	fmt.Println(VerifyChain(f, map[coz.B64s]*coz.Key{coz.B64s(k.Tmb): k}))
+
+	// Output:
+	// [alg now tmb typ msg]
+	// [alg now tmb typ msg prv_czd]
+	// <nil>
+}
+
+func TestVerifyChain(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "log.ndjson")
+	k, err := coz.NewKey(coz.SEAlg(coz.Ed25519))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[coz.B64s]*coz.Key{coz.B64s(k.Tmb): k}
+
+	l, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 5 {
+		_, err = l.Append(k, &coz.Pay{Now: coz.Timestamp(100 + i), Struct: &msg{fmt.Sprint(i)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// now must be monotonic.
+	if _, err = l.Append(k, &coz.Pay{Now: 1}); err == nil {
+		t.Fatal("expected error on decreasing now")
+	}
+	head := l.Head()
+	l.Close()
+
+	// Reopen recovers the head and continues the chain.
+	l, err = Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(head, l.Head()) || l.Len() != 5 {
+		t.Fatalf("expected head %s len 5, given %s %d", head, l.Head(), l.Len())
+	}
+	if _, err = l.Append(k, &coz.Pay{Now: 200}); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = VerifyChain(bytes.NewReader(b), keys); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing entry 2 breaks the link at 2.
+	lines := bytes.SplitAfter(b, []byte("\n"))
+	removed := bytes.Join(append(append([][]byte{}, lines[:2]...), lines[3:]...), nil)
+	err = VerifyChain(bytes.NewReader(removed), keys)
+	var cErr *ChainError
+	if !errors.As(err, &cErr) || cErr.Index != 2 {
+		t.Fatalf("expected ChainError at 2; given %v", err)
+	}
+
+	// Swapping entries 3 and 4 breaks at 3.
+	swapped := bytes.Join([][]byte{lines[0], lines[1], lines[2], lines[4], lines[3], lines[5]}, nil)
+	err = VerifyChain(bytes.NewReader(swapped), keys)
+	if !errors.As(err, &cErr) || cErr.Index != 3 {
+		t.Fatalf("expected ChainError at 3; given %v", err)
+	}
+
+	// Unknown key.
+	err = VerifyChain(bytes.NewReader(b), map[coz.B64s]*coz.Key{})
+	if !errors.As(err, &cErr) || cErr.Index != 0 {
+		t.Fatalf("expected ChainError at 0; given %v", err)
+	}
+}
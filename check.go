@@ -0,0 +1,79 @@
+package coz
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// MismatchError reports a coz field that differs from its recomputed or
+// required value, Want.  Field is "can", "cad", or "czd".
+type MismatchError struct {
+	Field string
+	Given string
+	Want  string
+}
+
+// Error implements error.
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("Coz: %s mismatch; expected %s, given %s", e.Field, e.Want, e.Given)
+}
+
+// Check recomputes `can`, `cad`, and `czd` with MetaWithAlg and errors if any
+// provided (non-empty) value differs.  Unlike Meta, Check does not modify cz,
+// so values sent by a peer are not silently overwritten.  Each mismatched field
+// is reported as a *MismatchError, joined with errors.Join.
+//
+// For contextual cozies lacking `pay.alg`, the alg of an embedded key is used.
+// Check does no cryptographic verification.
+func (cz *Coz) Check() error {
+	c := &Coz{Pay: cz.Pay, Sig: cz.Sig}
+	var alg SEAlg
+	if cz.Key != nil {
+		alg = cz.Key.Alg
+	}
+	err := c.MetaWithAlg(alg)
+	if err != nil {
+		return err
+	}
+	return cz.mismatch(c)
+}
+
+// mismatch returns a *MismatchError, joined with errors.Join, for each provided
+// (non-empty) `can`, `cad`, or `czd` of cz that differs from c.
+func (cz *Coz) mismatch(c *Coz) error {
+	var errs []error
+	if cz.Can != nil && !slices.Equal(cz.Can, c.Can) {
+		errs = append(errs, &MismatchError{Field: "can", Given: fmt.Sprintf("%q", cz.Can), Want: fmt.Sprintf("%q", c.Can)})
+	}
+	if len(cz.Cad) != 0 && !bytes.Equal(cz.Cad, c.Cad) {
+		errs = append(errs, &MismatchError{Field: "cad", Given: cz.Cad.String(), Want: c.Cad.String()})
+	}
+	if len(cz.Czd) != 0 && !bytes.Equal(cz.Czd, c.Czd) {
+		errs = append(errs, &MismatchError{Field: "czd", Given: cz.Czd.String(), Want: c.Czd.String()})
+	}
+	return errors.Join(errs...)
+}
+
+// VerifyCozCanon is VerifyCoz that additionally requires `can` of `pay` to
+// equal canon exactly, including order.  If cz.Can is provided, it must also
+// equal canon.  Provided `cad` and `czd` are checked as by Check.  On a canon
+// mismatch, VerifyCozCanon returns false and a *MismatchError.
+func (c *Key) VerifyCozCanon(cz *Coz, canon []string) (bool, error) {
+	if cz.Can != nil && !slices.Equal(cz.Can, canon) {
+		return false, &MismatchError{Field: "can", Given: fmt.Sprintf("%q", cz.Can), Want: fmt.Sprintf("%q", canon)}
+	}
+	can, err := Canon(cz.Pay)
+	if err != nil {
+		return false, err
+	}
+	if !slices.Equal(can, canon) {
+		return false, &MismatchError{Field: "can", Given: fmt.Sprintf("%q", can), Want: fmt.Sprintf("%q", canon)}
+	}
+	k := &Coz{Pay: cz.Pay, Key: c, Cad: cz.Cad, Sig: cz.Sig, Czd: cz.Czd}
+	if err = k.Check(); err != nil {
+		return false, err
+	}
+	return c.VerifyCoz(cz)
+}
@@ -0,0 +1,103 @@
+package coz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// goldenCozCzd is the `czd` of GoldenCoz.
+const goldenCozCzd = "xrYMu87EXes58PnEACcDW1t0jF2ez4FCN-njTF0MHNo"
+
+func ExampleCoz_Check() {
+	cz := new(Coz)
+	err := json.Unmarshal([]byte(GoldenCoz), cz)
+	if err != nil {
+		panic(err)
+	}
+	cz.Cad = MustDecode(GoldenCad)
+	cz.Czd = MustDecode(GoldenCad) // Wrong `czd` sent by a peer.
+	fmt.Println(cz.Check())
+
+	// Output:
+	// Coz: czd mismatch; expected xrYMu87EXes58PnEACcDW1t0jF2ez4FCN-njTF0MHNo, given XzrXMGnY0QFwAKkr43Hh-Ku3yUS8NVE0BdzSlMLSuTU
+}
+
+func TestCheck(t *testing.T) {
+	cz := new(Coz)
+	err := json.Unmarshal([]byte(GoldenCoz), cz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Nothing provided.
+	if err = cz.Check(); err != nil {
+		t.Fatal(err)
+	}
+	cz.Can = []string{"msg", "alg", "now", "tmb", "typ"}
+	cz.Cad = MustDecode(GoldenCad)
+	cz.Czd = MustDecode(goldenCozCzd)
+	if err = cz.Check(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every mismatched field is reported.
+	bad := *cz
+	bad.Can = []string{"alg"}
+	bad.Cad = MustDecode(goldenCozCzd)
+	bad.Czd = MustDecode(GoldenCad)
+	err = bad.Check()
+	for _, f := range []string{"can", "cad", "czd"} {
+		found := false
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			var me *MismatchError
+			if errors.As(e, &me) && me.Field == f {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected mismatch for %s in %v", f, err)
+		}
+	}
+	if cz.Czd.String() != goldenCozCzd {
+		t.Fatal("Check modified cz")
+	}
+
+	// Contextual coz uses the embedded key's alg.
+	ctx := &Coz{Pay: GoldenPayNoAlg, Key: &GoldenKey, Sig: []byte{1}}
+	if err = ctx.Check(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyCozCanon(t *testing.T) {
+	cz := new(Coz)
+	err := json.Unmarshal([]byte(GoldenCoz), cz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canon := []string{"msg", "alg", "now", "tmb", "typ"}
+	v, err := GoldenKey.VerifyCozCanon(cz, canon)
+	if err != nil || !v {
+		t.Fatalf("expected valid, given %v, %v", v, err)
+	}
+
+	var me *MismatchError
+	_, err = GoldenKey.VerifyCozCanon(cz, []string{"alg", "now", "tmb", "typ", "msg"})
+	if !errors.As(err, &me) || me.Field != "can" {
+		t.Fatalf("expected can mismatch, given %v", err)
+	}
+
+	cz.Can = []string{"alg"} // Provided `can` must equal canon.
+	_, err = GoldenKey.VerifyCozCanon(cz, canon)
+	if !errors.As(err, &me) || me.Field != "can" {
+		t.Fatalf("expected can mismatch, given %v", err)
+	}
+
+	cz.Can = canon
+	cz.Czd = MustDecode(GoldenCad)
+	_, err = GoldenKey.VerifyCozCanon(cz, canon)
+	if !errors.As(err, &me) || me.Field != "czd" {
+		t.Fatalf("expected czd mismatch, given %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Command coz-log is a self-hostable, append-only transparency log for published
+Coz keys and self-revokes.  Clients audit the log's signed tree heads and
+inclusion proofs to detect withheld keys or revokes.  See package merkle.
+
+A key-publish is a coz with `typ` "coz/key/publish" that embeds the public key
+in `key` and is signed by that key:
+
+	{
+	  "pay": {"alg":"ES256","now":1623132000,"tmb":"<tmb>","typ":"coz/key/publish"},
+	  "key": {"alg":"ES256","pub":"<pub>","tmb":"<tmb>"},
+	  "sig": "<sig>"
+	}
+
+A revoke is a self-revoke coz.  See Pay.IsRevoke.  If the revoked key has not
+been published, the revoke must embed the public key in `key`.
+
+The log's Merkle tree uses the hash alg of the log key, and commits to each
+entry's `czd`.  Entries must therefore be signed by keys whose alg uses the
+same hash alg; for example, an ES256 log accepts only ES256 keys.
+
+Endpoints:
+
+	POST /entries          Submit a key-publish or revoke coz.
+	GET  /entries          Entries as newline delimited cozies.  Optional
+	                       query "start" and "end".
+	GET  /sth              Signed tree head.
+	GET  /proof            Inclusion proof for query "czd" and optional "size".
+	GET  /consistency      Consistency proof for query "old" and "new".
+	GET  /keys/{tmb}       A published key, including `rvk` if revoked.
+	GET  /log-key          The log's public key.
+
+Usage:
+
+	coz-log -addr :8080 -data ./coz-log
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/cyphrme/coz"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	data := flag.String("data", "coz-log", "data directory")
+	alg := flag.String("alg", string(coz.ES256), "log key alg, used when creating the log key")
+	flag.Parse()
+
+	err := os.MkdirAll(*data, 0o700)
+	if err != nil {
+		log.Fatal(err)
+	}
+	key, err := loadOrCreateKey(filepath.Join(*data, "log-key.json"), coz.SEAlg(*alg))
+	if err != nil {
+		log.Fatal(err)
+	}
+	s, err := openServer(filepath.Join(*data, "entries.ndjson"), key)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer s.Close()
+
+	log.Printf("coz-log: key %s, %d entries, listening on %s", key.Tmb, s.tree.Size(), *addr)
+	log.Fatal(http.ListenAndServe(*addr, s.Handler()))
+}
+
+// loadOrCreateKey loads the log signing key from name, creating it with alg if
+// it does not exist.
+func loadOrCreateKey(name string, alg coz.SEAlg) (*coz.Key, error) {
+	key := new(coz.Key)
+	b, err := os.ReadFile(name)
+	if err == nil {
+		return key, key.UnmarshalJSON(b)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	key, err = coz.NewKey(alg)
+	if err != nil {
+		return nil, err
+	}
+	key.Tag = "coz-log"
+	b, err = coz.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+	return key, os.WriteFile(name, b, 0o600)
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/cyphrme/coz"
+	"github.com/cyphrme/coz/merkle"
+)
+
+// PublishTyp is the `pay.typ` of key-publish cozies.
+const PublishTyp = "coz/key/publish"
+
+// maxEntrySize is the maximum size of a submitted entry.
+const maxEntrySize = 64 << 10
+
+// errDuplicate is returned by submit for an entry already in the log.
+var errDuplicate = errors.New("entry already logged")
+
+// server is an append-only log of key-publish and revoke cozies.  Entries are
+// persisted to an NDJSON file, each with the public key embedded, and
+// committed to by a Merkle tree over `czd`.
+type server struct {
+	mu      sync.Mutex // Serializes appends.
+	key     *coz.Key   // Log signing key.
+	f       *os.File
+	enc     *coz.Encoder
+	tree    *merkle.Tree
+	entries []*coz.Coz
+	czds    map[coz.B64s]int // czd → index
+	keys    *coz.Keyring     // Published keys.
+	rvks    *coz.MemRevocationStore
+}
+
+// entryResponse is the response to a submitted entry.
+type entryResponse struct {
+	Index int     `json:"index"`
+	Czd   coz.B64 `json:"czd"`
+}
+
+// proofResponse is the response for an inclusion proof.
+type proofResponse struct {
+	Index int                    `json:"index"`
+	Czd   coz.B64                `json:"czd"`
+	Proof *merkle.InclusionProof `json:"proof"`
+}
+
+// openServer opens or creates the entries file name and re-verifies every
+// existing entry.  key signs tree heads.
+func openServer(name string, key *coz.Key) (*server, error) {
+	tree, err := merkle.New(key.Alg.Hash())
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	s := &server{
+		key:  key,
+		f:    f,
+		enc:  coz.NewEncoder(f),
+		tree: tree,
+		czds: make(map[coz.B64s]int),
+		keys: coz.NewKeyring(),
+		rvks: coz.NewMemRevocationStore(),
+	}
+
+	d := coz.NewDecoder(f)
+	d.SetMaxSize(maxEntrySize)
+	for {
+		cz, err := d.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err == nil {
+			cz, err = s.check(cz)
+		}
+		if err == nil {
+			err = s.apply(cz)
+		}
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("coz-log: %s line %d: %w", name, d.Line(), err)
+		}
+	}
+	return s, nil
+}
+
+// Close closes the entries file.
+func (s *server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+// check verifies that cz is a valid key-publish or revoke and returns the
+// entry to log, with the public key embedded and `czd` calculated.
+func (s *server) check(cz *coz.Coz) (*coz.Coz, error) {
+	if len(cz.Pay) == 0 || len(cz.Sig) == 0 {
+		return nil, errors.New("pay and sig must be set")
+	}
+	p := new(coz.Pay)
+	err := json.Unmarshal(cz.Pay, p)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Tmb) == 0 {
+		return nil, errors.New("pay.tmb must be set")
+	}
+
+	key := cz.Key
+	if key != nil {
+		if len(key.Prv) != 0 {
+			return nil, coz.ErrPrvPresent
+		}
+		if err = key.Correct(); err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(key.Tmb, p.Tmb) {
+			return nil, fmt.Errorf("key tmb %s does not match pay.tmb %s", key.Tmb, p.Tmb)
+		}
+	}
+
+	switch {
+	case p.IsRevoke():
+		if key == nil {
+			key, err = s.keys.Lookup(p.Tmb)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if _, err = coz.CheckRevoke(cz, key); err != nil {
+			return nil, err
+		}
+	case p.Typ == PublishTyp:
+		if key == nil {
+			return nil, errors.New("key-publish must embed key")
+		}
+		if s.rvks.IsRevokedAt(key.Tmb, p.Now) {
+			return nil, fmt.Errorf("key %s is revoked", key.Tmb)
+		}
+		v, err := key.VerifyCoz(cz)
+		if err != nil {
+			return nil, err
+		}
+		if !v {
+			return nil, errors.New("invalid signature")
+		}
+	default:
+		return nil, fmt.Errorf("coz is neither a revoke nor typ %q", PublishTyp)
+	}
+	// The tree commits to `czd`, which must be of the tree's HshAlg.
+	if h := key.Alg.Hash(); h != s.tree.Alg() {
+		return nil, fmt.Errorf("key alg %s hash %s does not match log hash %s", key.Alg, h, s.tree.Alg())
+	}
+
+	e := &coz.Coz{Pay: cz.Pay, Key: key.Public(), Sig: cz.Sig}
+	if err = e.MetaWithAlg(key.Alg); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// apply adds the checked entry e to the in-memory state, without persisting.
+// Caller must hold the lock or have exclusive access.
+func (s *server) apply(e *coz.Coz) error {
+	if _, ok := s.czds[coz.B64s(e.Czd)]; ok {
+		return errDuplicate
+	}
+	err := s.tree.Append(e.Czd)
+	if err != nil {
+		return err
+	}
+	if e.Parsed.IsRevoke() {
+		k := *e.Key // ApplyRevoke sets `rvk`, which is not part of the entry.
+		if err = s.rvks.ApplyRevoke(e, &k); err != nil {
+			return err
+		}
+	}
+	if err = s.keys.Add(e.Key); err != nil {
+		return err
+	}
+	s.czds[coz.B64s(e.Czd)] = len(s.entries)
+	s.entries = append(s.entries, e)
+	return nil
+}
+
+// submit verifies cz, appends it to the file, and returns the logged entry's
+// index and `czd`.  Duplicate entries return the existing index and
+// errDuplicate.
+func (s *server) submit(cz *coz.Coz) (*entryResponse, error) {
+	e, err := s.check(cz)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i, ok := s.czds[coz.B64s(e.Czd)]; ok {
+		return &entryResponse{Index: i, Czd: e.Czd}, errDuplicate
+	}
+	// Persist before applying so that the file is never behind the tree.
+	if err = s.enc.Encode(&coz.Coz{Pay: e.Pay, Key: e.Key, Sig: e.Sig}); err != nil {
+		return nil, err
+	}
+	if err = s.f.Sync(); err != nil {
+		return nil, err
+	}
+	if err = s.apply(e); err != nil {
+		return nil, err
+	}
+	return &entryResponse{Index: len(s.entries) - 1, Czd: e.Czd}, nil
+}
+
+// Handler returns the log's HTTP handler.
+func (s *server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /entries", s.handleSubmit)
+	mux.HandleFunc("GET /entries", s.handleEntries)
+	mux.HandleFunc("GET /sth", s.handleSTH)
+	mux.HandleFunc("GET /proof", s.handleProof)
+	mux.HandleFunc("GET /consistency", s.handleConsistency)
+	mux.HandleFunc("GET /keys/{tmb}", s.handleKey)
+	mux.HandleFunc("GET /log-key", s.handleLogKey)
+	return mux
+}
+
+func (s *server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	b, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxEntrySize))
+	if err != nil {
+		httpError(w, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	cz := new(coz.Coz)
+	if err = json.Unmarshal(b, cz); err != nil {
+		httpError(w, http.StatusBadRequest, err)
+		return
+	}
+	resp, err := s.submit(cz)
+	switch {
+	case errors.Is(err, errDuplicate):
+		writeJSON(w, http.StatusOK, resp)
+	case err != nil:
+		httpError(w, http.StatusBadRequest, err)
+	default:
+		writeJSON(w, http.StatusCreated, resp)
+	}
+}
+
+func (s *server) handleEntries(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	entries := s.entries
+	s.mu.Unlock()
+
+	start, end := 0, len(entries)
+	var err error
+	if v := r.URL.Query().Get("start"); v != "" {
+		if start, err = strconv.Atoi(v); err != nil {
+			httpError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("end"); v != "" {
+		if end, err = strconv.Atoi(v); err != nil {
+			httpError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if start < 0 || end > len(entries) || start > end {
+		httpError(w, http.StatusBadRequest, fmt.Errorf("invalid range [%d, %d) for size %d", start, end, len(entries)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := coz.NewEncoder(w)
+	for _, e := range entries[start:end] {
+		if err = enc.Encode(&coz.Coz{Pay: e.Pay, Key: e.Key, Sig: e.Sig}); err != nil {
+			return
+		}
+	}
+}
+
+func (s *server) handleSTH(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	sth, err := merkle.SignTreeHead(s.key, s.tree)
+	s.mu.Unlock()
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sth)
+}
+
+func (s *server) handleProof(w http.ResponseWriter, r *http.Request) {
+	czd, err := coz.Decode(r.URL.Query().Get("czd"))
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.czds[coz.B64s(czd)]
+	if !ok {
+		httpError(w, http.StatusNotFound, fmt.Errorf("czd %s not found", czd))
+		return
+	}
+	size := s.tree.Size()
+	if v := r.URL.Query().Get("size"); v != "" {
+		if size, err = strconv.Atoi(v); err != nil {
+			httpError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	p, err := s.tree.InclusionProof(i, size)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, &proofResponse{Index: i, Czd: czd, Proof: p})
+}
+
+func (s *server) handleConsistency(w http.ResponseWriter, r *http.Request) {
+	oldSize, err := strconv.Atoi(r.URL.Query().Get("old"))
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err)
+		return
+	}
+	newSize, err := strconv.Atoi(r.URL.Query().Get("new"))
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err)
+		return
+	}
+	p, err := s.tree.ConsistencyProof(oldSize, newSize)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *server) handleKey(w http.ResponseWriter, r *http.Request) {
+	tmb, err := coz.Decode(r.PathValue("tmb"))
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err)
+		return
+	}
+	k, err := s.keys.Lookup(tmb)
+	if err != nil {
+		httpError(w, http.StatusNotFound, err)
+		return
+	}
+	if rvk, ok := s.rvks.Rvk(tmb); ok {
+		k.Rvk = rvk
+	}
+	writeJSON(w, http.StatusOK, k)
+}
+
+func (s *server) handleLogKey(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.key.Public())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	b, err := coz.Marshal(v)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
+}
+
+func httpError(w http.ResponseWriter, status int, err error) {
+	http.Error(w, err.Error(), status)
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/cyphrme/coz"
+	"github.com/cyphrme/coz/merkle"
+)
+
+func newTestServer(t *testing.T, name string, logKey *coz.Key) (*server, *httptest.Server) {
+	t.Helper()
+	s, err := openServer(name, logKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(func() {
+		ts.Close()
+		s.Close()
+	})
+	return s, ts
+}
+
+func publish(t *testing.T, k *coz.Key) *coz.Coz {
+	t.Helper()
+	cz, err := k.SignPay(&coz.Pay{Alg: k.Alg, Now: coz.Now(), Tmb: k.Tmb, Typ: PublishTyp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cz.Key = k.Public()
+	return cz
+}
+
+func post(t *testing.T, ts *httptest.Server, cz *coz.Coz) (int, *entryResponse) {
+	t.Helper()
+	b, err := coz.Marshal(cz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(ts.URL+"/entries", "application/json", bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	er := new(entryResponse)
+	if resp.StatusCode < 300 {
+		if err = json.NewDecoder(resp.Body).Decode(er); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode, er
+}
+
+func get(t *testing.T, ts *httptest.Server, path string, v any) int {
+	t.Helper()
+	resp, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK && v != nil {
+		if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestServer(t *testing.T) {
+	logKey, err := coz.NewKey(coz.SEAlg(coz.ES256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(t.TempDir(), "entries.ndjson")
+	_, ts := newTestServer(t, name, logKey)
+
+	logPub := new(coz.Key)
+	get(t, ts, "/log-key", logPub)
+	k1, _ := coz.NewKey(coz.SEAlg(coz.ES256))
+	k2, _ := coz.NewKey(coz.SEAlg(coz.ES256))
+
+	pub1 := publish(t, k1)
+	if status, er := post(t, ts, pub1); status != http.StatusCreated || er.Index != 0 {
+		t.Fatalf("publish: status %d index %d", status, er.Index)
+	}
+	sth1 := new(coz.Coz)
+	if status := get(t, ts, "/sth", sth1); status != http.StatusOK {
+		t.Fatalf("sth: status %d", status)
+	}
+	th1, _, err := merkle.VerifyTreeHead(logPub, sth1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, er := post(t, ts, pub1); status != http.StatusOK || er.Index != 0 {
+		t.Fatalf("duplicate: status %d index %d", status, er.Index)
+	}
+	if status, _ := post(t, ts, publish(t, k2)); status != http.StatusCreated {
+		t.Fatalf("publish: status %d", status)
+	}
+
+	// Revoke of a published key need not embed the key.
+	rvk, err := k1.Revoke()
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, rvkEntry := post(t, ts, rvk)
+	if status != http.StatusCreated || rvkEntry.Index != 2 {
+		t.Fatalf("revoke: status %d index %d", status, rvkEntry.Index)
+	}
+
+	// Rejections.
+	bad := publish(t, k2)
+	bad.Sig[0] ^= 1
+	unpublished, _ := coz.NewKey(coz.SEAlg(coz.ES256))
+	unRvk, _ := unpublished.Revoke()
+	withPrv := publish(t, k2)
+	withPrv.Key = k2
+	msg, _ := k2.SignPay(&coz.Pay{Alg: k2.Alg, Tmb: k2.Tmb, Typ: "example/msg"})
+	es384, _ := coz.NewKey(coz.SEAlg(coz.ES384))
+	for name, cz := range map[string]*coz.Coz{
+		"bad signature":      bad,
+		"unpublished revoke": unRvk,
+		"prv present":        withPrv,
+		"not a publish":      msg,
+		"hash alg mismatch":  publish(t, es384),
+	} {
+		if status, _ := post(t, ts, cz); status != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, given %d", name, status)
+		}
+	}
+
+	// Signed tree head and inclusion proof.
+	sth := new(coz.Coz)
+	if status := get(t, ts, "/sth", sth); status != http.StatusOK {
+		t.Fatalf("sth: status %d", status)
+	}
+	th, _, err := merkle.VerifyTreeHead(logPub, sth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if th.Size != 3 {
+		t.Fatalf("expected size 3, given %d", th.Size)
+	}
+	pr := new(proofResponse)
+	if status := get(t, ts, "/proof?czd="+url.QueryEscape(rvkEntry.Czd.String()), pr); status != http.StatusOK {
+		t.Fatalf("proof: status %d", status)
+	}
+	if err = pr.Proof.Verify(rvkEntry.Czd, th.Root); err != nil {
+		t.Fatal(err)
+	}
+
+	// Consistency with an earlier size.
+	cp := new(merkle.ConsistencyProof)
+	if status := get(t, ts, "/consistency?old=1&new=3", cp); status != http.StatusOK {
+		t.Fatalf("consistency: status %d", status)
+	}
+	if err = cp.Verify(th1.Root, th.Root); err != nil {
+		t.Fatal(err)
+	}
+	cp.Path[0][0] ^= 1
+	if err = cp.Verify(th1.Root, th.Root); err == nil {
+		t.Fatal("expected error for altered consistency proof")
+	}
+
+	// Key lookup reports revocation.
+	k := new(coz.Key)
+	if status := get(t, ts, "/keys/"+k1.Tmb.String(), k); status != http.StatusOK {
+		t.Fatalf("key: status %d", status)
+	}
+	if k.Rvk == 0 || len(k.Prv) != 0 {
+		t.Fatalf("expected revoked public key, given %s", k)
+	}
+
+	// Entries are auditable: recompute the root from the served entries.
+	resp, err := http.Get(ts.URL + "/entries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	tr, _ := merkle.New(logKey.Alg.Hash())
+	d := coz.NewDecoder(resp.Body)
+	d.SetMeta(true)
+	for {
+		cz, err := d.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = tr.Append(cz.Czd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root, _ := tr.Root()
+	if !bytes.Equal(root, th.Root) {
+		t.Fatal("root of served entries does not match tree head")
+	}
+}
+
+func TestServerReopen(t *testing.T) {
+	logKey, err := coz.NewKey(coz.SEAlg(coz.ES256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(t.TempDir(), "entries.ndjson")
+	s, err := openServer(name, logKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, _ := coz.NewKey(coz.SEAlg(coz.ES256))
+	if _, err = s.submit(publish(t, k)); err != nil {
+		t.Fatal(err)
+	}
+	rvk, _ := k.Revoke()
+	if _, err = s.submit(rvk); err != nil {
+		t.Fatal(err)
+	}
+	root, _ := s.tree.Root()
+	s.Close()
+
+	s, err = openServer(name, logKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	root2, _ := s.tree.Root()
+	if s.tree.Size() != 2 || !bytes.Equal(root, root2) {
+		t.Fatalf("reopened log mismatch: size %d", s.tree.Size())
+	}
+	if _, ok := s.rvks.Rvk(k.Tmb); !ok {
+		t.Fatal("revoke not restored")
+	}
+}
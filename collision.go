@@ -0,0 +1,190 @@
+package coz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// payStdFields are the standard `pay` fields of Pay.
+var payStdFields = []string{"alg", "now", "tmb", "typ", "rvk"}
+
+// FieldCollisionError is returned when a custom `pay` field collides with a
+// standard `pay` field.  Type is the Go type of the custom struct, if known.
+type FieldCollisionError struct {
+	Field string
+	Type  string
+}
+
+// Error implements error.
+func (e *FieldCollisionError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("Pay: custom field %q collides with standard field", e.Field)
+	}
+	return fmt.Sprintf("Pay: custom field %q of %s collides with standard field", e.Field, e.Type)
+}
+
+// PayFieldOwner may be implemented by a Pay.Struct to take ownership of
+// standard `pay` fields, for example a struct with its own `typ` field.  Owned
+// fields are marshaled only from the struct and the corresponding Pay field
+// must be zero.  On unmarshal, owned fields are unmarshaled only into the
+// struct and the corresponding Pay field is left zero, so that the pay may be
+// marshaled again.  Names are compared case insensitively.
+type PayFieldOwner interface {
+	OwnedPayFields() []string
+}
+
+// checkStruct errors with *FieldCollisionError if p.Struct has a field named
+// as a standard field it does not own, or if p sets a field owned by p.Struct.
+func (p *Pay) checkStruct() error {
+	owned := ownedFields(p.Struct)
+	typ := reflect.TypeOf(p.Struct)
+	err := checkTypeCollision(typ, owned)
+	if err != nil {
+		return err
+	}
+	for _, f := range owned {
+		if p.isSet(f) {
+			return &FieldCollisionError{Field: f, Type: derefType(typ).String()}
+		}
+	}
+	return nil
+}
+
+// clearOwned zeros the standard fields of p owned by p.Struct.
+func (p *Pay) clearOwned() {
+	for _, f := range ownedFields(p.Struct) {
+		switch strings.ToLower(f) {
+		case "alg":
+			p.Alg = ""
+		case "now":
+			p.Now = 0
+		case "tmb":
+			p.Tmb = nil
+		case "typ":
+			p.Typ = ""
+		case "rvk":
+			p.Rvk = 0
+		}
+	}
+}
+
+// isSet reports whether the standard field name is non-zero in p.
+func (p *Pay) isSet(name string) bool {
+	switch strings.ToLower(name) {
+	case "alg":
+		return p.Alg != ""
+	case "now":
+		return p.Now != 0
+	case "tmb":
+		return len(p.Tmb) != 0
+	case "typ":
+		return p.Typ != ""
+	case "rvk":
+		return p.Rvk != 0
+	}
+	return false
+}
+
+// ownedFields returns the standard fields owned by v, if any.
+func ownedFields(v any) []string {
+	if o, ok := v.(PayFieldOwner); ok {
+		return o.OwnedPayFields()
+	}
+	return nil
+}
+
+// checkTypeCollision errors if struct type t has a JSON field named as a
+// standard `pay` field, regardless of `omitempty`.  Fields of embedded structs
+// are included.  Names are compared case insensitively since encoding/json
+// matches names case insensitively on unmarshal.  Fields in owned are
+// permitted.  Non-struct types are not checked.
+func checkTypeCollision(t reflect.Type, owned []string) error {
+	for _, name := range jsonFieldNames(t) {
+		if slices.ContainsFunc(owned, func(o string) bool { return strings.EqualFold(o, name) }) {
+			continue
+		}
+		for _, std := range payStdFields {
+			if strings.EqualFold(name, std) {
+				return &FieldCollisionError{Field: name, Type: derefType(t).String()}
+			}
+		}
+	}
+	return nil
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// jsonFieldNames returns the JSON names of the fields of struct type t,
+// following encoding/json's rules for tags and embedded structs.
+func jsonFieldNames(t reflect.Type) (names []string) {
+	t = derefType(t)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			names = append(names, jsonFieldNames(ft)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// checkCollision errors if a field appears more than once in the top level of
+// the marshaled `pay` b.  Since the standard fields and the custom struct are
+// each free of duplicates, any duplicate is a collision.  This catches
+// collisions not visible from a type, such as map keys and custom marshalers.
+func checkCollision(b []byte, typ string) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != json.Delim('{') {
+		return errors.New("Pay: expected JSON object")
+	}
+	seen := make(map[string]bool)
+	for dec.More() {
+		t, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		k := t.(string)
+		var v json.RawMessage
+		if err = dec.Decode(&v); err != nil {
+			return err
+		}
+		if seen[k] {
+			return &FieldCollisionError{Field: k, Type: typ}
+		}
+		seen[k] = true
+	}
+	return nil
+}
@@ -22,7 +22,10 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"reflect"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/sha3"
@@ -51,12 +54,16 @@ type Coz struct {
 	Czd B64             `json:"czd,omitempty"`
 
 	Parsed *Pay `json:"-"`
+
+	// Limits optionally overrides DefaultLimits for UnmarshalJSON.
+	Limits *Limits `json:"-"`
 }
 
 // String implements fmt.Stringer.  Without this method `pay` prints as bytes.
-// On error, returns the error as a string.
+// On error, returns the error as a string.  `prv` of an embedded key is
+// redacted.
 func (cz Coz) String() string {
-	b, err := Marshal(cz)
+	b, err := Marshal(redact(cz))
 	if err != nil {
 		return err.Error()
 	}
@@ -91,8 +98,11 @@ func (cz *Coz) Meta() (err error) {
 //
 // MetaWithAlg does no cryptographic verification.
 func (cz *Coz) MetaWithAlg(alg SEAlg) (err error) {
-	// Set Parsed from Pay.
-	err = json.Unmarshal(cz.Pay, &cz.Parsed)
+	// Set Parsed from Pay.  Pay was bounded when received, so no limits apply.
+	if cz.Parsed == nil {
+		cz.Parsed = new(Pay)
+	}
+	err = cz.Parsed.unmarshal(cz.Pay, Limits{})
 	if err != nil {
 		return err
 	}
@@ -124,8 +134,15 @@ func (cz *Coz) MetaWithAlg(alg SEAlg) (err error) {
 
 // UnmarshalJSON unmarshals checks for duplicates and unmarshals `coz`.
 // See notes on Pay.UnmarshalJSON.
+//
+// Coz.Limits, if set before unmarshalling, bounds the input, otherwise
+// DefaultLimits is used.
 func (cz *Coz) UnmarshalJSON(b []byte) error {
-	err := checkDuplicate(json.NewDecoder(bytes.NewReader(b)))
+	l := cz.limits()
+	if l.MaxCozSize > 0 && len(b) > l.MaxCozSize {
+		return &LimitError{Limit: "MaxCozSize", Max: l.MaxCozSize}
+	}
+	err := checkLimits(b, l)
 	if err != nil {
 		return err
 	}
@@ -134,14 +151,25 @@ func (cz *Coz) UnmarshalJSON(b []byte) error {
 	cz2 := new(coz2)
 	cz2.Parsed = cz.Parsed
 	cz2.Key = cz.Key
+	cz2.Limits = cz.Limits
 	err = json.Unmarshal(b, cz2)
 	if err != nil {
 		return err
 	}
+	if l.MaxPaySize > 0 && len(cz2.Pay) > l.MaxPaySize {
+		return &LimitError{Limit: "MaxPaySize", Max: l.MaxPaySize}
+	}
 	*cz = *(*Coz)(cz2)
 	return nil
 }
 
+func (cz *Coz) limits() Limits {
+	if cz.Limits != nil {
+		return *cz.Limits
+	}
+	return DefaultLimits()
+}
+
 // CzdCanon is the canon for a `czd`.
 var CzdCanon = []string{"cad", "sig"}
 
@@ -177,6 +205,72 @@ type Pay struct {
 
 	// Custom arbitrary struct given by application.
 	Struct any `json:"-"`
+
+	// Limits optionally overrides DefaultLimits for UnmarshalJSON.
+	Limits *Limits `json:"-"`
+
+	// tilde is the tilde encapsulated sub-object `~`.  See SetTilde.
+	tilde json.RawMessage
+
+	// raw is the unmarshalled `pay`, from which Object reads custom fields that
+	// Pay does not otherwise retain.
+	raw json.RawMessage
+}
+
+// Object returns the current fields of p as an Object, so that custom fields
+// may be read without unmarshalling into a struct.  Fields are in the order p
+// marshals.  For an unmarshalled pay, including Coz.Parsed from Meta, with a
+// nil Struct, the custom fields of the `pay` are included after the other
+// fields and before `~`.  Object is built on each call, so changes to p are
+// reflected.
+//
+// For the fields of a received `pay` in the received order, unmarshal the
+// `pay` into an Object instead.
+func (p *Pay) Object() (*Object, error) {
+	b, err := p.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	o := new(Object)
+	err = o.unmarshal(b, Limits{}) // p is already in memory.
+	if err != nil {
+		return nil, err
+	}
+	if p.raw == nil || p.Struct != nil {
+		return o, nil
+	}
+	extra, err := extraFields(p.raw)
+	if err != nil {
+		return nil, err
+	}
+	at := o.Len()
+	if _, ok := o.Get(TildeField); ok {
+		at--
+	}
+	for k, v := range extra.All() {
+		if _, ok := o.Get(k); !ok {
+			o.Insert(at, k, v)
+			at++
+		}
+	}
+	return o, nil
+}
+
+// extraFields returns the fields of the JSON object b that are neither
+// standard `pay` fields nor `~`.  Standard fields are matched case
+// insensitively, as by encoding/json.
+func extraFields(b []byte) (*Object, error) {
+	o := new(Object)
+	err := o.unmarshal(b, Limits{}) // b is checked by the caller.
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range o.Keys() {
+		if k == TildeField || slices.ContainsFunc(payStdFields, func(s string) bool { return strings.EqualFold(s, k) }) {
+			o.Delete(k)
+		}
+	}
+	return o, nil
 }
 
 // Coz returns a new coz with only Pay populated.
@@ -199,37 +293,78 @@ func (p Pay) String() string {
 // MarshalJSON promotes the embedded field "Struct" to top level JSON.
 // Solution from Jonathan Hall:
 // https://jhall.io/posts/go-json-tricks-embedded-marshaler
+//
+// MarshalJSON errors with *FieldCollisionError if a field of `Struct` has the
+// same JSON name as a standard field, even if the field is `omitempty` or in
+// an embedded struct, unless `Struct` owns the field.  See PayFieldOwner.
+//
+// If set, `~` is always the last field.  See SetTilde.
 func (p *Pay) MarshalJSON() ([]byte, error) {
 	type pay2 Pay // Break infinite Marshal loop
 
-	pay, err := Marshal((*pay2)(p))
+	if p.Struct == nil {
+		pay, err := Marshal((*pay2)(p))
+		if err != nil {
+			return nil, err
+		}
+		return p.appendTilde(pay, "")
+	}
+
+	err := p.checkStruct()
 	if err != nil {
 		return nil, err
 	}
-	if p.Struct == nil {
-		return pay, nil
+	typ := reflect.TypeOf(p.Struct)
+	pay, err := Marshal((*pay2)(p))
+	if err != nil {
+		return nil, err
 	}
 
 	s, err := json.Marshal(p.Struct)
 	if err != nil {
 		return nil, err
 	}
-	// Concatenate the two:
-	s[0] = ','
-	return append(pay[:len(pay)-1], s...), nil
+	// Concatenate the two, taking care that either may be empty ("{}").
+	b := pay
+	switch {
+	case string(s) == "{}":
+	case string(pay) == "{}":
+		b = s
+	default:
+		s[0] = ','
+		b = append(pay[:len(pay)-1], s...)
+		if err = checkCollision(b, derefType(typ).String()); err != nil {
+			return nil, err
+		}
+	}
+	return p.appendTilde(b, derefType(typ).String())
 }
 
 // UnmarshalJSON unmarshals both Pay and if given custom Pay.Struct. Throws an
 // error on duplicate. (Duplicate related, see
 // https://github.com/golang/go/issues/48298)
 func (p *Pay) UnmarshalJSON(b []byte) error {
-	err := checkDuplicate(json.NewDecoder(bytes.NewReader(b)))
+	l := DefaultLimits()
+	if p.Limits != nil {
+		l = *p.Limits
+	}
+	return p.unmarshal(b, l)
+}
+
+// unmarshal is UnmarshalJSON bounded by l instead of p.Limits.  Zero l is used
+// to re-parse a `pay` already in memory, which was bounded when received.
+func (p *Pay) unmarshal(b []byte, l Limits) error {
+	if l.MaxPaySize > 0 && len(b) > l.MaxPaySize {
+		return &LimitError{Limit: "MaxPaySize", Max: l.MaxPaySize}
+	}
+	err := checkLimits(b, l)
 	if err != nil {
 		return err
 	}
 
 	type pay2 Pay // Break infinite unmarshal loop
 	p2 := new(pay2)
+	p2.Limits = p.Limits
 	err = json.Unmarshal(b, p2)
 	if err != nil {
 		return err
@@ -241,7 +376,13 @@ func (p *Pay) UnmarshalJSON(b []byte) error {
 			return err
 		}
 		p2.Struct = str
+		(*Pay)(p2).clearOwned()
+	}
+	p2.tilde, err = tildeValue(b)
+	if err != nil {
+		return err
 	}
+	p2.raw = bytes.Clone(b)
 
 	// if p2.Now > MaxSafeTimestamp || p2.Now < 0 {
 	// 	return fmt.Errorf("Pay.UnmarshalJSON: values for now must be between 0 and 2^53 - 1")
@@ -319,7 +460,16 @@ type Marshaler interface {
 //
 // Go structs already require unique fields, so unlike coz.UnmarshalJSON or
 // pay.UnmarshalJSON, marshaling will not sanitize for duplicates.
+//
+// When built with the tag coz_jsonv2, Marshal uses encoding/json/v2, which
+// does not HTML escape, with options for output identical to encoding/json
+// except that invalid UTF-8 errors.  See json_v2.go.
 func Marshal(i any) ([]byte, error) {
+	return marshal(i)
+}
+
+// marshalV1 is Marshal using encoding/json.
+func marshalV1(i any) ([]byte, error) {
 	buffer := &bytes.Buffer{}
 	encoder := json.NewEncoder(buffer)
 	encoder.SetEscapeHTML(false)
@@ -332,7 +482,12 @@ func Marshal(i any) ([]byte, error) {
 
 // MarshalPretty uses 4 spaces for each level. Spaces are used instead of tabs
 // because some applications display tabs as 8 spaces, which is excessive.
+//
+// Since MarshalPretty is intended for human readable output, such as logging,
+// `prv` is redacted from Key, *Key, and keys embedded in Coz and *Coz.  Use
+// Marshal for the full key.
 func MarshalPretty(i any) ([]byte, error) {
+	i = redact(i)
 	buffer := &bytes.Buffer{}
 	encoder := json.NewEncoder(buffer)
 	encoder.SetIndent("", "    ")
@@ -344,6 +499,32 @@ func MarshalPretty(i any) ([]byte, error) {
 	return bytes.TrimRight(buffer.Bytes(), "\n"), nil
 }
 
+// redact returns a copy of i with `prv` removed if i is a key or a coz with an
+// embedded private key.  Otherwise i is returned unmodified.
+func redact(i any) any {
+	switch v := i.(type) {
+	case Key:
+		v.Prv = nil
+		return v
+	case *Key:
+		if v != nil && len(v.Prv) != 0 {
+			k := *v
+			k.Prv = nil
+			return &k
+		}
+	case Coz:
+		v.Key, _ = redact(v.Key).(*Key)
+		return v
+	case *Coz:
+		if v != nil && v.Key != nil && len(v.Key.Prv) != 0 {
+			cz := *v
+			cz.Key, _ = redact(v.Key).(*Key)
+			return &cz
+		}
+	}
+	return i
+}
+
 // Hash hashes msg and returns the digest. Returns nil on error. Errors on
 // invalid HshAlg or if the resulting digest is empty (as a sanity check).
 //
@@ -394,8 +575,20 @@ func isRevoke(rvk Timestamp) bool {
 type ErrJSONDuplicate error
 
 // checkDuplicate checks for JSON duplicates. See notes on Marshal and the
-// README FAQ on duplicate fields.
+// README FAQ on duplicate fields.  Nesting is bounded by DefaultMaxDepth.
 func checkDuplicate(d *json.Decoder) error {
+	return checkJSON(d, Limits{MaxDepth: DefaultMaxDepth}, 1)
+}
+
+// checkLimitsV1 is checkLimits using encoding/json.
+func checkLimitsV1(b []byte, l Limits) error {
+	return checkJSON(json.NewDecoder(bytes.NewReader(b)), l, 1)
+}
+
+// checkJSON checks for JSON duplicates and that the structural limits of l
+// (MaxDepth, MaxFields, and MaxStringLen) are not exceeded.  depth is the depth
+// of the next value, starting at 1.
+func checkJSON(d *json.Decoder, l Limits, depth int) error {
 	t, err := d.Token()
 	if err != nil {
 		return err
@@ -404,8 +597,14 @@ func checkDuplicate(d *json.Decoder) error {
 	// Is it a delimiter?
 	delim, ok := t.(json.Delim)
 	if !ok {
+		if s, ok := t.(string); ok && l.MaxStringLen > 0 && len(s) > l.MaxStringLen {
+			return &LimitError{Limit: "MaxStringLen", Max: l.MaxStringLen}
+		}
 		return nil // scaler type, nothing to do
 	}
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return &LimitError{Limit: "MaxDepth", Max: l.MaxDepth}
+	}
 
 	switch delim {
 	case '{':
@@ -421,9 +620,15 @@ func checkDuplicate(d *json.Decoder) error {
 				return ErrJSONDuplicate(fmt.Errorf("Coz: JSON duplicate field %q", key))
 			}
 			keys[key] = true
+			if l.MaxFields > 0 && len(keys) > l.MaxFields {
+				return &LimitError{Limit: "MaxFields", Max: l.MaxFields}
+			}
+			if l.MaxStringLen > 0 && len(key) > l.MaxStringLen {
+				return &LimitError{Limit: "MaxStringLen", Max: l.MaxStringLen}
+			}
 
 			// Recursive, Check value in case value is object.
-			err = checkDuplicate(d)
+			err = checkJSON(d, l, depth+1)
 			if err != nil {
 				return err
 			}
@@ -435,7 +640,7 @@ func checkDuplicate(d *json.Decoder) error {
 
 	case '[':
 		for d.More() {
-			if err := checkDuplicate(d); err != nil {
+			if err := checkJSON(d, l, depth+1); err != nil {
 				return err
 			}
 		}
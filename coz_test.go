@@ -2,6 +2,7 @@ package coz
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -238,6 +239,132 @@ func ExamplePay_UnmarshalJSON_duplicate() {
 	// Coz: JSON duplicate field "alg"
 }
 
+// Example demonstrating that marshalling a `pay` whose custom struct has a
+// field named as a standard field results in an error, even when `omitempty`.
+func ExamplePay_MarshalJSON_collision() {
+	type Msg struct {
+		Typ string `json:"typ,omitempty"`
+		Msg string `json:"msg"`
+	}
+	p := &Pay{Typ: "cyphr.me/msg/create", Struct: &Msg{Msg: "hi"}}
+	_, err := p.MarshalJSON()
+	fmt.Println(err)
+
+	// Output:
+	// Pay: custom field "typ" of coz.Msg collides with standard field
+}
+
+// typedMsg owns `typ`.  See PayFieldOwner.
+type typedMsg struct {
+	Typ string `json:"typ"`
+	Msg string `json:"msg"`
+}
+
+func (typedMsg) OwnedPayFields() []string { return []string{"typ"} }
+
+// ExamplePayFieldOwner demonstrates a custom struct owning `typ`.
+func ExamplePayFieldOwner() {
+	p := &Pay{Alg: GoldenKey.Alg, Struct: &typedMsg{Typ: "cyphr.me/msg/create", Msg: "hi"}}
+	fmt.Println(p)
+
+	p.Typ = "cyphr.me/msg/update" // Pay must not also set an owned field.
+	_, err := p.MarshalJSON()
+	fmt.Println(err)
+
+	// Output:
+	// {"alg":"ES256","typ":"cyphr.me/msg/create","msg":"hi"}
+	// Pay: custom field "typ" of coz.typedMsg collides with standard field
+}
+
+// upperOwner owns `typ`, named in a different case than the standard field.
+type upperOwner struct {
+	Typ string `json:"typ"`
+}
+
+func (upperOwner) OwnedPayFields() []string { return []string{"TYP"} }
+
+// TestPayFieldOwner_roundTrip tests that an unmarshalled pay with an owned
+// field marshals again to the same JSON.
+func TestPayFieldOwner_roundTrip(t *testing.T) {
+	in := `{"alg":"ES256","typ":"cyphr.me/msg/create","msg":"hi"}`
+
+	p := &Pay{Struct: new(typedMsg)}
+	err := json.Unmarshal([]byte(in), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Typ != "" || p.Struct.(*typedMsg).Typ != "cyphr.me/msg/create" {
+		t.Fatalf("expected typ only in Struct, given %q and %q", p.Typ, p.Struct.(*typedMsg).Typ)
+	}
+	b, err := Marshal(p)
+	if err != nil || string(b) != in {
+		t.Fatalf("Pay: given %s, %v", b, err)
+	}
+
+	tp := new(TypedPay[typedMsg])
+	err = json.Unmarshal([]byte(in), tp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = Marshal(tp)
+	if err != nil || string(b) != in {
+		t.Fatalf("TypedPay: given %s, %v", b, err)
+	}
+
+	// Owned names are compared case insensitively.
+	u := &Pay{Struct: new(upperOwner)}
+	err = json.Unmarshal([]byte(`{"typ":"x"}`), u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = Marshal(u)
+	if err != nil || string(b) != `{"typ":"x"}` {
+		t.Fatalf("case: given %s, %v", b, err)
+	}
+	u.Typ = "y"
+	var ce *FieldCollisionError
+	if _, err = Marshal(u); !errors.As(err, &ce) {
+		t.Fatalf("case: expected *FieldCollisionError, given %v", err)
+	}
+}
+
+func TestPayMarshalJSON_collision(t *testing.T) {
+	type Inner struct {
+		Now int64 `json:"now,omitempty"`
+	}
+	type embeddedPtr struct {
+		*Inner
+		Msg string `json:"msg"`
+	}
+	type unexportedEmbed struct {
+		inner
+	}
+	for _, s := range []any{
+		&embeddedPtr{Msg: "m"},
+		unexportedEmbed{},
+		map[string]any{"rvk": 1},
+		json.RawMessage(`{"rvk":1}`),
+	} {
+		_, err := Marshal(&Pay{Rvk: 1, Now: 1, Struct: s})
+		var ce *FieldCollisionError
+		if !errors.As(err, &ce) {
+			t.Errorf("%T: expected *FieldCollisionError, given %v", s, err)
+		}
+	}
+
+	// No collision when the standard field is unset and the struct is a map
+	// or raw JSON, since collisions are then only known by value.
+	b, err := Marshal(&Pay{Alg: GoldenKey.Alg, Struct: map[string]any{"rvk": 1}})
+	if err != nil || string(b) != `{"alg":"ES256","rvk":1}` {
+		t.Fatalf("given %s, %v", b, err)
+	}
+}
+
+// inner is embedded unexported, and its fields are still promoted.
+type inner struct {
+	Alg string `json:"alg"`
+}
+
 // Example demonstrating that unmarshalling a `coz` that has duplicate field
 // names results in an error.
 func ExampleCoz_UnmarshalJSON_duplicate() {
@@ -0,0 +1,260 @@
+package coz
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// HardenedOffset is the index offset for hardened child keys.  In paths,
+// hardened indexes are denoted by a trailing `'` or `H`, e.g. "m/0'/1H".
+const HardenedOffset uint32 = 0x80000000
+
+// slip10Curves are the SLIP-0010 master key HMAC keys.  Only algorithms with
+// curves defined by SLIP-0010 are supported.
+// https://github.com/satoshilabs/slips/blob/master/slip-0010.md
+var slip10Curves = map[SigAlg]string{
+	ES256:   "Nist256p1 seed",
+	Ed25519: "ed25519 seed",
+}
+
+// slip10Node is an extended private key.
+type slip10Node struct {
+	k []byte // Private key.
+	c []byte // Chain code.
+}
+
+// ExtendedKey is a key with its SLIP-0010 chain code.  Children are derived
+// from an ExtendedKey so that paths compose: deriving "m/1'" from the extended
+// key at "m/0'" equals deriving "m/0'/1'" from the master.
+//
+// An ExtendedKey without `prv` (see Public) derives only non-hardened ES256
+// children, so that a third party may verify such children without the
+// parent's `prv`.  Sharing a public ExtendedKey reveals every non-hardened
+// descendant, and together with any non-hardened child `prv`, the parent `prv`.
+type ExtendedKey struct {
+	Key   *Key `json:"key"`
+	Chain B64  `json:"chain"` // Chain code.
+}
+
+// Extended returns the SLIP-0010 master extended key of c, with c's `prv` used
+// as the SLIP-0010 seed.  Supported algs are ES256 and Ed25519.
+func (c *Key) Extended() (*ExtendedKey, error) {
+	if len(c.Prv) == 0 {
+		return nil, errors.New("Extended: key has no prv")
+	}
+	curve, ok := slip10Curves[c.Alg.SigAlg()]
+	if !ok {
+		return nil, fmt.Errorf("Extended: unsupported alg %q", c.Alg)
+	}
+	n, err := slip10Master(c.Alg.SigAlg(), curve, c.Prv)
+	if err != nil {
+		return nil, err
+	}
+	m := &Key{Alg: c.Alg, Prv: n.k, Now: c.Now, Tag: "m"}
+	m.Pub = m.calcPub()
+	return &ExtendedKey{Key: m, Chain: n.c}, m.Thumbprint()
+}
+
+// DeriveChild derives the child key at path, e.g. "m/0'/1'", from the master
+// extended key of c.  See Key.Extended.  Since c is always treated as a root,
+// DeriveChild of a child does not compose with the parent's path; use
+// ExtendedKey.Derive instead.  The child's `tag` is set to path and `now` is
+// copied from c.
+//
+// Supported algs are ES256 and Ed25519.  Ed25519 only supports hardened
+// derivation.
+func (c *Key) DeriveChild(path string) (*Key, error) {
+	x, err := c.Extended()
+	if err != nil {
+		return nil, fmt.Errorf("DeriveChild: %w", err)
+	}
+	x, err = x.Derive(path)
+	if err != nil {
+		return nil, err
+	}
+	return x.Key, nil
+}
+
+// Derive derives the extended child at path, relative to x.  The child's `tag`
+// is set to path and `now` is copied from x.Key.  Without `prv`, only
+// non-hardened ES256 paths may be derived.
+func (x *ExtendedKey) Derive(path string) (*ExtendedKey, error) {
+	alg := x.Key.Alg.SigAlg()
+	if _, ok := slip10Curves[alg]; !ok {
+		return nil, fmt.Errorf("DeriveChild: unsupported alg %q", x.Key.Alg)
+	}
+	if len(x.Chain) != 32 {
+		return nil, fmt.Errorf("DeriveChild: incorrect chain code length; expected 32, given %d", len(x.Chain))
+	}
+	idx, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	child := &Key{Alg: x.Key.Alg, Now: x.Key.Now, Tag: path}
+	c := x.Chain
+	if len(x.Key.Prv) != 0 {
+		n := &slip10Node{k: x.Key.Prv, c: c}
+		for _, i := range idx {
+			n, err = n.child(alg, i)
+			if err != nil {
+				return nil, err
+			}
+		}
+		child.Prv, c = n.k, n.c
+		child.Pub = child.calcPub()
+	} else {
+		pub := x.Key.Pub
+		for _, i := range idx {
+			pub, c, err = publicChild(alg, pub, c, i)
+			if err != nil {
+				return nil, err
+			}
+		}
+		child.Pub = pub
+	}
+	return &ExtendedKey{Key: child, Chain: c}, child.Thumbprint()
+}
+
+// Public returns x without `prv`.  See ExtendedKey.
+func (x *ExtendedKey) Public() *ExtendedKey {
+	return &ExtendedKey{Key: x.Key.Public(), Chain: bytes.Clone(x.Chain)}
+}
+
+// VerifyChild reports whether childTmb is the thumbprint of the key derived
+// from parent at path.  If parent has `prv`, any path may be verified.
+// Otherwise, parent is a public ExtendedKey and path must be non-hardened
+// ES256, which proves derivation to a third party without the parent's `prv`.
+func VerifyChild(parent *ExtendedKey, path string, childTmb B64) (bool, error) {
+	child, err := parent.Derive(path)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(child.Key.Tmb, childTmb), nil
+}
+
+// ParseDerivationPath parses a path such as "m/0'/1H/2" into indexes, with
+// HardenedOffset added to hardened indexes.
+func ParseDerivationPath(path string) (idx []uint32, err error) {
+	parts := strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("ParseDerivationPath: path must begin with \"m\"; given %q", path)
+	}
+	for _, p := range parts[1:] {
+		var h uint32
+		if strings.HasSuffix(p, "'") || strings.HasSuffix(p, "H") {
+			h = HardenedOffset
+			p = p[:len(p)-1]
+		}
+		i, err := strconv.ParseUint(p, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("ParseDerivationPath: invalid index %q in path %q", p, path)
+		}
+		idx = append(idx, uint32(i)+h)
+	}
+	return idx, nil
+}
+
+// slip10Master generates the master node from seed.
+func slip10Master(alg SigAlg, curve string, seed []byte) (*slip10Node, error) {
+	I := hmacSHA512([]byte(curve), seed)
+	if alg == Ed25519 {
+		return &slip10Node{k: I[:32], c: I[32:]}, nil
+	}
+	n := curveOrders[alg]
+	for {
+		il := new(big.Int).SetBytes(I[:32])
+		if il.Sign() != 0 && il.Cmp(n) < 0 {
+			return &slip10Node{k: I[:32], c: I[32:]}, nil
+		}
+		I = hmacSHA512([]byte(curve), I)
+	}
+}
+
+// child derives child node i (CKDpriv).
+func (p *slip10Node) child(alg SigAlg, i uint32) (*slip10Node, error) {
+	var data []byte
+	if i >= HardenedOffset {
+		data = append([]byte{0}, p.k...)
+	} else {
+		if alg == Ed25519 {
+			return nil, errors.New("DeriveChild: Ed25519 only supports hardened derivation")
+		}
+		curve := SEAlg(alg).Curve().EllipticCurve()
+		x, y := curve.ScalarBaseMult(p.k)
+		data = elliptic.MarshalCompressed(curve, x, y)
+	}
+	data = binary.BigEndian.AppendUint32(data, i)
+
+	I := hmacSHA512(p.c, data)
+	if alg == Ed25519 {
+		return &slip10Node{k: I[:32], c: I[32:]}, nil
+	}
+
+	n := curveOrders[alg]
+	for {
+		il := new(big.Int).SetBytes(I[:32])
+		if il.Cmp(n) < 0 {
+			k := il.Add(il, new(big.Int).SetBytes(p.k))
+			k.Mod(k, n)
+			if k.Sign() != 0 {
+				return &slip10Node{k: k.FillBytes(make([]byte, 32)), c: I[32:]}, nil
+			}
+		}
+		data = append([]byte{1}, I[32:]...)
+		data = binary.BigEndian.AppendUint32(data, i)
+		I = hmacSHA512(p.c, data)
+	}
+}
+
+// publicChild derives the public key and chain code of non-hardened child i
+// from pub and chain code c (SLIP-0010 CKDpub).  Only ES256 is supported.
+func publicChild(alg SigAlg, pub, c []byte, i uint32) (B64, []byte, error) {
+	if i >= HardenedOffset {
+		return nil, nil, errors.New("DeriveChild: hardened derivation requires prv")
+	}
+	if alg != ES256 {
+		return nil, nil, fmt.Errorf("DeriveChild: public derivation is not supported for alg %q", alg)
+	}
+	size := SEAlg(alg).PubSize()
+	if len(pub) != size {
+		return nil, nil, fmt.Errorf("DeriveChild: incorrect pub length; expected %d, given %d", size, len(pub))
+	}
+	curve := SEAlg(alg).Curve().EllipticCurve()
+	px, py := new(big.Int).SetBytes(pub[:size/2]), new(big.Int).SetBytes(pub[size/2:])
+	if !curve.IsOnCurve(px, py) {
+		return nil, nil, errors.New("DeriveChild: pub is not on curve")
+	}
+	data := elliptic.MarshalCompressed(curve, px, py)
+	data = binary.BigEndian.AppendUint32(data, i)
+
+	I := hmacSHA512(c, data)
+	n := curveOrders[alg]
+	for {
+		il := new(big.Int).SetBytes(I[:32])
+		if il.Cmp(n) < 0 {
+			x, y := curve.ScalarBaseMult(I[:32])
+			x, y = curve.Add(x, y, px, py)
+			if x.Sign() != 0 || y.Sign() != 0 { // Not the point at infinity.
+				return PadInts(x, y, size), I[32:], nil
+			}
+		}
+		data = append([]byte{1}, I[32:]...)
+		data = binary.BigEndian.AppendUint32(data, i)
+		I = hmacSHA512(c, data)
+	}
+}
+
+func hmacSHA512(key, data []byte) []byte {
+	h := hmac.New(sha512.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
@@ -0,0 +1,194 @@
+package coz
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+// ExampleKey_DeriveChild demonstrates deriving a child key from a parent and
+// verifying that the child was derived from the parent.
+func ExampleKey_DeriveChild() {
+	child, err := GoldenKey.DeriveChild("m/0'/1'")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(child.Alg, child.Tag, child.Correct())
+
+	m, err := GoldenKey.Extended()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(VerifyChild(m, "m/0'/1'", child.Tmb))
+	fmt.Println(VerifyChild(m, "m/0'/2'", child.Tmb))
+
+	// Output:
+	// ES256 m/0'/1' <nil>
+	// true <nil>
+	// false <nil>
+}
+
+// ExampleExtendedKey demonstrates a third party verifying a non-hardened child
+// with only the parent's public extended key.
+func ExampleExtendedKey() {
+	m, err := GoldenKey.Extended()
+	if err != nil {
+		panic(err)
+	}
+	parent, err := m.Derive("m/0'")
+	if err != nil {
+		panic(err)
+	}
+	child, err := parent.Derive("m/1/2")
+	if err != nil {
+		panic(err)
+	}
+
+	pub := parent.Public()
+	fmt.Println(VerifyChild(pub, "m/1/2", child.Key.Tmb))
+	fmt.Println(VerifyChild(pub, "m/1/3", child.Key.Tmb))
+	fmt.Println(VerifyChild(pub, "m/1'", child.Key.Tmb))
+
+	// Output:
+	// true <nil>
+	// false <nil>
+	// false DeriveChild: hardened derivation requires prv
+}
+
+// TestSLIP10 uses SLIP-0010 test vector 1.
+// https://github.com/satoshilabs/slips/blob/master/slip-0010.md
+func TestSLIP10(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		alg  SigAlg
+		path string
+		prv  string
+		cc   string
+	}{
+		{Ed25519, "m", "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7", "90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb"},
+		{Ed25519, "m/0H", "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3", "8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69"},
+		{ES256, "m", "612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2", "beeb672fe4621673f722f38529c07392fecaa61015c80c34f29ce8b41b3cb6ea"},
+		{ES256, "m/0H", "6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c", "3460cea53e6a6bb5fb391eeef3237ffd8724bf0a40e94943c98b83825342ee11"},
+		{ES256, "m/0H/1", "284e9d38d07d21e4e281b645089a94f4cf5a5a81369acf151a1c3a57f18b2129", "4187afff1aafa8445010097fb99d23aee9f599450c7bd140b6826ac22ba21d0c"},
+	}
+	for _, tt := range tests {
+		n, err := slip10Master(tt.alg, slip10Curves[tt.alg], seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		idx, err := ParseDerivationPath(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, i := range idx {
+			n, err = n.child(tt.alg, i)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if hex.EncodeToString(n.k) != tt.prv || hex.EncodeToString(n.c) != tt.cc {
+			t.Fatalf("%s %s: expected %s %s, given %x %x", tt.alg, tt.path, tt.prv, tt.cc, n.k, n.c)
+		}
+	}
+}
+
+func TestKey_DeriveChild(t *testing.T) {
+	k, err := NewSigningKey(Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = k.DeriveChild("m/0")
+	if err == nil {
+		t.Fatal("Ed25519 must error on non-hardened derivation")
+	}
+	c, err := k.DeriveChild("m/0'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Valid() {
+		t.Fatal("derived Ed25519 key is invalid")
+	}
+
+	_, err = GoldenKey.DeriveChild("0'/1'")
+	if err == nil {
+		t.Fatal("expected error on path without m")
+	}
+	k, err = NewSigningKey(ES384)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = k.DeriveChild("m/0'")
+	if err == nil {
+		t.Fatal("expected error on unsupported alg")
+	}
+}
+
+func TestExtendedKey_Derive(t *testing.T) {
+	for _, alg := range []SigAlg{ES256, Ed25519} {
+		k, err := NewSigningKey(alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := k.Extended()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Paths compose.
+		a, err := m.Derive("m/0'")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ab, err := a.Derive("m/1'")
+		if err != nil {
+			t.Fatal(err)
+		}
+		full, err := k.DeriveChild("m/0'/1'")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(ab.Key.Prv, full.Prv) || !bytes.Equal(ab.Key.Tmb, full.Tmb) {
+			t.Fatalf("%s: derivation does not compose", alg)
+		}
+	}
+
+	// Public derivation equals private derivation.
+	m, err := GoldenKey.Extended()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"m", "m/0", "m/0/1", "m/2147483647/7"} {
+		prv, err := m.Derive(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, err := m.Public().Derive(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pub.Key.Prv) != 0 || !bytes.Equal(prv.Key.Pub, pub.Key.Pub) || !bytes.Equal(prv.Chain, pub.Chain) {
+			t.Fatalf("%s: public derivation mismatch", path)
+		}
+		if v, err := VerifyChild(m.Public(), path, prv.Key.Tmb); err != nil || !v {
+			t.Fatalf("%s: VerifyChild %v, %v", path, v, err)
+		}
+	}
+
+	// Ed25519 has no public derivation.
+	k, err := NewSigningKey(Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := k.Extended()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = e.Public().Derive("m/0"); err == nil {
+		t.Fatal("expected error on Ed25519 public derivation")
+	}
+	e.Chain = e.Chain[:16]
+	if _, err = e.Derive("m/0'"); err == nil {
+		t.Fatal("expected error on short chain code")
+	}
+}
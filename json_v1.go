@@ -0,0 +1,24 @@
+//go:build !coz_jsonv2
+
+package coz
+
+import "encoding/json"
+
+func marshal(i any) ([]byte, error) {
+	return marshalV1(i)
+}
+
+// compact is a helper that compactifies JSON.
+func compact(msg json.RawMessage) ([]byte, error) {
+	return compactV1(msg)
+}
+
+// checkLimits checks b for duplicates and that l is not exceeded.  Size limits
+// are checked by callers.
+func checkLimits(b []byte, l Limits) error {
+	return checkLimitsV1(b, l)
+}
+
+func canon(raw json.RawMessage, l Limits) ([]string, error) {
+	return canonV1(raw, l)
+}
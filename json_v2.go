@@ -0,0 +1,124 @@
+//go:build coz_jsonv2 && goexperiment.jsonv2 && go1.27
+
+// The encoding/json/v2 backend is opt-in with the build tag coz_jsonv2, e.g.:
+//
+//	go build -tags coz_jsonv2 .
+//
+// It requires Go 1.27 or later with GOEXPERIMENT=jsonv2, which Go 1.27 enables
+// by default, and otherwise fails to build.  Without the tag, encoding/json is
+// used regardless of GOEXPERIMENT.  See json_v1.go.
+
+package coz
+
+import (
+	"bytes"
+	jsonv1 "encoding/json"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// marshalOptions are the encoding/json/v2 options giving output byte identical
+// to Marshal built with encoding/json, so that `cad` and `czd` do not depend
+// on the backend.  v2 does not escape HTML by default.  Unlike encoding/json,
+// duplicate names and invalid UTF-8 error instead of being passed through or
+// replaced.
+var marshalOptions = json.JoinOptions(
+	json.Deterministic(true), // Sort map keys.
+	json.FormatNilSliceAsNull(true),
+	json.FormatNilMapAsNull(true),
+	jsonv1.OmitEmptyWithLegacySemantics(true), // e.g. omit `now` of 0.
+	jsonv1.CallMethodsWithLegacySemantics(true),
+	jsonv1.FormatByteArrayAsArray(true),
+	jsonv1.FormatBytesWithLegacySemantics(true),
+	jsonv1.FormatDurationAsNano(true),
+	jsonv1.StringifyWithLegacySemantics(true),
+	jsonv1.ReportErrorsWithLegacySemantics(true),
+	jsontext.EscapeForJS(true),
+	jsontext.PreserveRawStrings(true),
+)
+
+func marshal(i any) ([]byte, error) {
+	b, err := json.Marshal(i, marshalOptions)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// compact is a helper that compactifies JSON.  Duplicate names and invalid
+// UTF-8 error.
+func compact(msg jsonv1.RawMessage) ([]byte, error) {
+	v := jsontext.Value(bytes.Clone(msg))
+	err := v.Compact(jsontext.AllowDuplicateNames(false), jsontext.AllowInvalidUTF8(false))
+	if err != nil {
+		return nil, duplicateError(err)
+	}
+	return v, nil
+}
+
+// checkLimits checks b for duplicates and that l is not exceeded.  Size limits
+// are checked by callers.  jsontext rejects duplicate names and invalid UTF-8
+// natively.
+func checkLimits(b []byte, l Limits) error {
+	_, err := walk(b, l)
+	return err
+}
+
+// canon returns the top level names of raw in order, as decoded natively by
+// jsontext.
+func canon(raw jsonv1.RawMessage, l Limits) ([]string, error) {
+	if k := jsontext.Value(raw).Kind(); k != '{' {
+		return nil, fmt.Errorf("Coz: canon input is not a JSON object, given %v", k)
+	}
+	return walk(raw, l)
+}
+
+// walk reads every token of b, checking the structural limits of l, and
+// returns the names of top level object members in order.
+func walk(b []byte, l Limits) (names []string, err error) {
+	dec := jsontext.NewDecoder(bytes.NewReader(b))
+	for {
+		tok, err := dec.ReadToken()
+		if err == io.EOF {
+			return names, nil
+		}
+		if err != nil {
+			return nil, duplicateError(err)
+		}
+		depth := dec.StackDepth()
+		switch tok.Kind() {
+		case '{', '[':
+			if l.MaxDepth > 0 && depth > l.MaxDepth {
+				return nil, &LimitError{Limit: "MaxDepth", Max: l.MaxDepth}
+			}
+		case '"':
+			s := tok.String()
+			if l.MaxStringLen > 0 && len(s) > l.MaxStringLen {
+				return nil, &LimitError{Limit: "MaxStringLen", Max: l.MaxStringLen}
+			}
+			kind, n := dec.StackIndex(depth)
+			if kind != '{' || n%2 == 0 { // Not a name.
+				continue
+			}
+			if l.MaxFields > 0 && (n+1)/2 > int64(l.MaxFields) {
+				return nil, &LimitError{Limit: "MaxFields", Max: l.MaxFields}
+			}
+			if depth == 1 {
+				names = append(names, s)
+			}
+		}
+	}
+}
+
+// duplicateError returns ErrJSONDuplicate, with the same message as
+// encoding/json builds, for jsontext duplicate name errors.
+func duplicateError(err error) error {
+	var se *jsontext.SyntacticError
+	if errors.As(err, &se) && errors.Is(err, jsontext.ErrDuplicateName) {
+		return ErrJSONDuplicate(fmt.Errorf("Coz: JSON duplicate field %q", se.JSONPointer.LastToken()))
+	}
+	return err
+}
@@ -0,0 +1,250 @@
+//go:build coz_jsonv2 && goexperiment.jsonv2 && go1.27
+
+package coz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// Differential tests between the encoding/json/v2 backend and encoding/json.
+// Run with:
+//
+//	go test -tags coz_jsonv2 -run Backend .
+
+var backendCorpus = []string{
+	GoldenPay,
+	GoldenCoz,
+	GoldenCozWKey,
+	GoldenKeyString,
+	string(GoldenPayNoAlg),
+	`{"msg":"<a href=\"x\">&amp;</a>","alg":"ES256"}`,
+	`{"msg":"line\u2028para\u2029","tab":"\t","esc":"\u00e9\u0041\/"}`,
+	`{"emoji":"😀","escaped":"\ud83d\ude00","cjk":"漢字"}`,
+	`{ "n" : [ 0, -1, 1.5, 1e+21, 1E3, 0.10 ], "b" : [true, false, null] }`,
+	`{"a":{"b":{"c":[{"d":[]},{}]}},"~":{"x":1}}`,
+	`{}`,
+}
+
+func TestBackendCompact(t *testing.T) {
+	for _, s := range backendCorpus {
+		want, err := compactV1(json.RawMessage(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := compact(json.RawMessage(s))
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("compact differs:\n%s\n%s", got, want)
+		}
+
+		wantCan, err := canonV1(json.RawMessage(s), DefaultLimits())
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotCan, err := canon(json.RawMessage(s), DefaultLimits())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equalStrings(gotCan, wantCan) {
+			t.Errorf("canon differs: %v %v", gotCan, wantCan)
+		}
+	}
+}
+
+func TestBackendMarshal(t *testing.T) {
+	pay := new(Pay)
+	err := json.Unmarshal([]byte(GoldenPay), pay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cz := new(Coz)
+	err = json.Unmarshal([]byte(GoldenCozWKey), cz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := &struct {
+		Msg  string         `json:"msg"`
+		Tags []string       `json:"tags"`
+		Meta map[string]any `json:"meta,omitempty"`
+		N    float64        `json:"n"`
+		Raw  []byte         `json:"raw,omitempty"`
+	}{Msg: "<&> \u2028 😀", Meta: map[string]any{"z": 1, "a": []int{}, "é": nil}, N: 1e21}
+	tilde := &Pay{Alg: SEAlg(ES256), Typ: "a"}
+	if err = tilde.SetTilde(map[string]any{"b": 2, "a": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []any{
+		pay,
+		*pay,
+		&Pay{Alg: SEAlg(ES256), Now: 1623132000, Struct: msg},
+		tilde,
+		cz,
+		&GoldenKey,
+		GoldenKey.Public(),
+		msg,
+		map[string]any{"b": 1, "a": "&", "A": nil, "ä": 1.5},
+		Timestamp(9007199254740991),
+		[]B64{nil, {}, {1, 2}},
+		json.RawMessage(` { "a" : 1 } `),
+	} {
+		want, err := marshalV1(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := marshal(v)
+		if err != nil {
+			t.Fatalf("%T: %v", v, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%T Marshal differs:\n%s\n%s", v, got, want)
+		}
+	}
+}
+
+// TestBackendCad ensures `cad` and `czd` are byte identical across backends,
+// both for cozies parsed from JSON and for pays signed from Go values.
+func TestBackendCad(t *testing.T) {
+	for _, s := range []string{GoldenCoz, GoldenCozWKey} {
+		cz := new(Coz)
+		err := json.Unmarshal([]byte(s), cz)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = cz.Meta()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := compactV1(cz.Pay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cad, err := Hash(GoldenKey.Alg.Hash(), b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		czd, err := GenCzd(GoldenKey.Alg.Hash(), cad, cz.Sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(cz.Cad, cad) || !bytes.Equal(cz.Czd, czd) {
+			t.Errorf("cad/czd differ: %s %s, %s %s", cz.Cad, cz.Czd, cad, czd)
+		}
+		if cz.Czd.String() != goldenCozCzd {
+			t.Errorf("czd %s, want %s", cz.Czd, goldenCozCzd)
+		}
+	}
+
+	pay := &Pay{Alg: GoldenKey.Alg, Now: 1623132000, Tmb: GoldenKey.Tmb, Typ: "cyphr.me/msg/create",
+		Struct: &struct {
+			Msg string `json:"msg"`
+		}{"<&> \u2028"}}
+	cz, err := GoldenKey.SignPay(pay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := marshalV1(pay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(cz.Pay, b) {
+		t.Errorf("signed pay differs:\n%s\n%s", cz.Pay, b)
+	}
+	if v, err := GoldenKey.VerifyCoz(cz); !v || err != nil {
+		t.Errorf("expected valid, given %v, %v", v, err)
+	}
+}
+
+// TestBackendRejects ensures the v2 backend rejects duplicate names and invalid
+// UTF-8, which encoding/json passes through.
+func TestBackendRejects(t *testing.T) {
+	dup := json.RawMessage(`{"a":1,"a":2}`)
+	if _, err := compactV1(dup); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := compact(dup); err == nil {
+		t.Error("compact: expected duplicate error")
+	}
+	for _, err := range []error{checkLimits(dup, Limits{}), func() error { _, err := canon(dup, Limits{}); return err }()} {
+		var e ErrJSONDuplicate
+		if !errors.As(err, &e) || err.Error() != `Coz: JSON duplicate field "a"` {
+			t.Errorf("expected ErrJSONDuplicate, given %v", err)
+		}
+	}
+
+	bad := json.RawMessage("{\"a\":\"\xff\"}")
+	if _, err := compactV1(bad); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := compact(bad); err == nil {
+		t.Error("compact: expected invalid UTF-8 error")
+	}
+	if err := checkLimits(bad, Limits{}); err == nil {
+		t.Error("checkLimits: expected invalid UTF-8 error")
+	}
+}
+
+// FuzzBackend ensures the backends agree on input without invalid UTF-8, lone
+// surrogates, or duplicates.
+func FuzzBackend(f *testing.F) {
+	for _, s := range backendCorpus {
+		f.Add([]byte(s), 4)
+	}
+	f.Add([]byte(`[[[{"a":"bbbbbbbbbb"}]]]`), 3)
+	f.Fuzz(func(t *testing.T, b []byte, n int) {
+		if n <= 0 || n > 16 || ValidateJSON(b, ParseOptions{UTF8: true}) != nil {
+			return
+		}
+		l := Limits{MaxDepth: n, MaxFields: n, MaxStringLen: n * 2}
+		if checkLimitsV1(b, Limits{}) != nil {
+			return // Duplicates.
+		}
+		if e1, e2 := checkLimitsV1(b, l), checkLimits(b, l); (e1 == nil) != (e2 == nil) {
+			t.Fatalf("limits differ for %q: %v, %v", b, e1, e2)
+		}
+		want, err := compactV1(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := compact(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("compact differs for %q:\n%s\n%s", b, got, want)
+		}
+		if jsonKind(b) != '{' {
+			return
+		}
+		c1, err1 := canonV1(b, Limits{})
+		c2, err2 := canon(b, Limits{})
+		if (err1 == nil) != (err2 == nil) || !equalStrings(c1, c2) {
+			t.Fatalf("canon differs for %q: %v %v, %v %v", b, c1, err1, c2, err2)
+		}
+	})
+}
+
+func jsonKind(b []byte) byte {
+	b = bytes.TrimLeft(b, " \t\r\n")
+	if len(b) == 0 {
+		return 0
+	}
+	return b[0]
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -40,8 +40,10 @@ type Key struct {
 	Pub B64       `json:"pub,omitempty"`
 }
 
-// String implements Stringer. Returns empty on error.
+// String implements Stringer. Returns empty on error.  `prv` is redacted so
+// that keys may be safely logged.  Use Marshal for the full key.
 func (c Key) String() string {
+	c.Prv = nil
 	b, err := Marshal(c)
 	if err != nil {
 		return ""
@@ -106,12 +108,16 @@ func Thumbprint(c *Key) (tmb B64, err error) {
 	return CanonicalHash(b, KeyCanon, c.Alg.Hash())
 }
 
-// UnmarshalJSON always populates `tmb` even if it isn't given.
+// UnmarshalJSON always populates `tmb` even if it isn't given.  Errors with
+// ErrKeyEncrypted if given an encrypted key.
 func (c *Key) UnmarshalJSON(b []byte) error {
 	err := checkDuplicate(json.NewDecoder(bytes.NewReader(b)))
 	if err != nil {
 		return err
 	}
+	if isEncryptedKey(b) {
+		return ErrKeyEncrypted
+	}
 
 	type key2 Key // Break infinite unmarshal loop
 	czk2 := new(key2)
@@ -144,6 +150,7 @@ func (c *Key) Sign(digest B64) (sig B64, err error) {
 	case ECDSA:
 		curve := c.Alg.Curve().EllipticCurve()
 		d := new(big.Int).SetBytes(c.Prv)
+		defer clear(d.Bits()) // Zero the private scalar copy.
 		// Go 1.24+ requires PublicKey.X and PublicKey.Y to be populated.
 		var pubX, pubY *big.Int
 		if len(c.Pub) == c.Alg.PubSize() {
@@ -178,6 +185,7 @@ func (c *Key) Sign(digest B64) (sig B64, err error) {
 		return PadInts(r, s, c.Alg.SigAlg().SigSize()), nil
 	case EdDSA:
 		pk := ed25519.NewKeyFromSeed(c.Prv)
+		defer clear(pk)
 		// Alternatively, concat prv with pub
 		// b := make([]coz.B64, 64)
 		// prv := append(b, c.Prv, c.Pub)
@@ -215,7 +223,7 @@ func (c *Key) SignPayRaw(p *Pay) (coz *Coz, err error) {
 // documentation on SignPay.
 func (c *Key) SignPayJSON(pay json.RawMessage) (coz *Coz, err error) {
 	p := new(Pay)
-	err = json.Unmarshal(pay, p)
+	err = p.unmarshal(pay, Limits{})
 	if err != nil {
 		return nil, err
 	}
@@ -316,7 +324,7 @@ func (c *Key) Verify(digest, sig B64) (valid bool) {
 // pay.tmb and uses key as a source of truth.
 func (c *Key) VerifyCoz(cz *Coz) (bool, error) {
 	p := new(Pay)
-	err := json.Unmarshal(cz.Pay, p)
+	err := p.unmarshal(cz.Pay, Limits{})
 	if err != nil {
 		return false, err
 	}
@@ -458,6 +466,14 @@ func (c Key) IsRevoked() bool {
 	return isRevoke(c.Rvk)
 }
 
+// Destroy zeroes `prv` in place and sets it to nil.  Other references to the
+// same underlying bytes are also zeroed.  Copies made elsewhere, for example by
+// marshaling, are not affected.
+func (c *Key) Destroy() {
+	clear(c.Prv)
+	c.Prv = nil
+}
+
 // calcPub recalculates 'pub' from 'prv' and returns 'pub'. 'pub' will not be set on the
 // key from here. Algorithms are constant-time.
 // https://cs.opensource.google/go/go/+/refs/tags/go1.18.3:src/crypto/elliptic/elliptic.go;l=455;drc=7f9494c277a471f6f47f4af3036285c0b1419816
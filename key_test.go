@@ -1,6 +1,7 @@
 package coz
 
 import (
+	"context"
 	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
@@ -109,11 +110,12 @@ type CustomStruct struct {
 	Msg string `json:"msg,omitempty"`
 }
 
+// ExampleKey_String demonstrates that `prv` is redacted when printing a key.
 func ExampleKey_String() {
 	fmt.Printf("%s\n", GoldenKey)
 
 	// Output:
-	// {"alg":"ES256","prv":"bNstg4_H3m3SlROufwRSEgibLrBuRq9114OvdapcpVA","now":1623132000,"tag":"Zami's Majuscule Key.","tmb":"U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg","pub":"2nTOaFVm2QLxmUO_SjgyscVHBtvHEfo2rq65MvgNRjORojq39Haq9rXNxvXxwba_Xj0F5vZibJR3isBdOWbo5g"}
+	// {"alg":"ES256","now":1623132000,"tag":"Zami's Majuscule Key.","tmb":"U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg","pub":"2nTOaFVm2QLxmUO_SjgyscVHBtvHEfo2rq65MvgNRjORojq39Haq9rXNxvXxwba_Xj0F5vZibJR3isBdOWbo5g"}
 }
 
 // ExampleKey_jsonUnmarshal tests unmarshalling a Coz key.
@@ -123,7 +125,11 @@ func ExampleKey_jsonUnmarshal() {
 	if err != nil {
 		panic(err)
 	}
-	fmt.Printf("%+v\n", Key)
+	b, err := Marshal(Key)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", b)
 
 	// Output:
 	//{"alg":"ES256","prv":"bNstg4_H3m3SlROufwRSEgibLrBuRq9114OvdapcpVA","now":1623132000,"tag":"Zami's Majuscule Key.","tmb":"U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg","pub":"2nTOaFVm2QLxmUO_SjgyscVHBtvHEfo2rq65MvgNRjORojq39Haq9rXNxvXxwba_Xj0F5vZibJR3isBdOWbo5g"}
@@ -454,6 +460,45 @@ func BenchmarkNSV(b *testing.B) {
 	}
 }
 
+// BenchmarkNSVBatch benchmarks verifying 1000 cozies per alg, like
+// BenchmarkNSV, sequentially with VerifyCoz and concurrently with VerifyBatch.
+// go test -bench=BenchmarkNSVBatch
+func BenchmarkNSVBatch(b *testing.B) {
+	algs := []SigAlg{ES224, ES256, ES384, ES512, Ed25519}
+	for _, alg := range algs {
+		items := batchItems(b, alg, 1000)
+		b.Run(string(alg)+"/VerifyCoz", func(b *testing.B) {
+			for j := 0; j < b.N; j++ {
+				for _, it := range items {
+					if v, err := it.Key.VerifyCoz(it.Coz); !v || err != nil {
+						b.Fatalf("The signature was invalid.  Alg: %s; %v", alg, err)
+					}
+				}
+			}
+		})
+		b.Run(string(alg)+"/VerifyBatch", func(b *testing.B) {
+			for j := 0; j < b.N; j++ {
+				for _, r := range VerifyBatch(context.Background(), items, 0) {
+					if !r.Valid {
+						b.Fatalf("The signature was invalid.  Alg: %s; %v", alg, r.Err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func ExampleKey_Destroy() {
+	gk2 := GoldenKey // Make a copy
+	gk2.Prv = append(B64(nil), GoldenKey.Prv...)
+	prv := gk2.Prv
+	gk2.Destroy()
+	fmt.Println(gk2.Prv, prv)
+
+	// Output:
+	//  AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA
+}
+
 func ExampleKey_IsRevoked() {
 	gk2 := GoldenKey // Make a copy
 	fmt.Println(gk2.IsRevoked())
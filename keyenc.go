@@ -0,0 +1,220 @@
+package coz
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDF and AEAD identifiers for encrypted Coz keys.
+const (
+	KDFScrypt             = "scrypt"
+	AEADXChaCha20Poly1305 = "XChaCha20-Poly1305"
+)
+
+// Default scrypt parameters for Key.Encrypt.  N=2^15, r=8, p=1 is the
+// interactive login recommendation from the scrypt paper and uses 32 MiB of
+// memory.
+const (
+	ScryptN = 1 << 15
+	ScryptR = 8
+	ScryptP = 1
+)
+
+// scryptMaxN limits the cost of decrypting a key from an untrusted source.
+const scryptMaxN = 1 << 20
+
+// saltSize is the size of a generated salt and the minimum accepted.
+const saltSize = 16
+
+// ErrKeyEncrypted is returned when an encrypted key is given where a plaintext
+// key is expected, for example by Key.UnmarshalJSON.  Use DecryptKey instead.
+var ErrKeyEncrypted = errors.New("Coz: key is encrypted; use DecryptKey")
+
+// KeyEnc holds the parameters and ciphertext for an encrypted `prv`.  `ct` is
+// the AEAD sealed `prv` with additional data being every cleartext field of the
+// key, so that none may be modified or removed, for example `rvk`.  See
+// EncryptedKey.
+//
+//	`kdf`   - Key derivation function.  Only "scrypt" is supported.
+//	`salt`  - KDF salt.
+//	`n`,`r`,`p` - scrypt cost parameters.
+//	`aead`  - AEAD cipher.  Only "XChaCha20-Poly1305" is supported.
+//	`nonce` - AEAD nonce.
+//	`ct`    - Ciphertext of `prv` with the AEAD tag appended.
+type KeyEnc struct {
+	KDF   string `json:"kdf"`
+	Salt  B64    `json:"salt"`
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	AEAD  string `json:"aead"`
+	Nonce B64    `json:"nonce"`
+	Ct    B64    `json:"ct"`
+}
+
+// EncryptedKey is a Coz key whose `prv` is replaced with `enc`. The public
+// fields remain in the clear so that encrypted keys may still be indexed and
+// identified without the passphrase.
+type EncryptedKey struct {
+	Alg SEAlg     `json:"alg"`
+	Enc *KeyEnc   `json:"enc"`
+	Now Timestamp `json:"now,omitempty"`
+	Tag string    `json:"tag,omitempty"`
+	Rvk Timestamp `json:"rvk,omitempty"`
+	Tmb B64       `json:"tmb"`
+	Typ string    `json:"typ,omitempty"`
+	Pub B64       `json:"pub"`
+}
+
+// Encrypt returns the JSON encoded EncryptedKey of a private key using the
+// given passphrase.  `prv` is wrapped with scrypt and XChaCha20-Poly1305.  The
+// key must be correct and have `prv` set.  The key itself is not modified.
+func (c *Key) Encrypt(passphrase []byte) ([]byte, error) {
+	return c.encrypt(passphrase, ScryptN, ScryptR, ScryptP)
+}
+
+func (c *Key) encrypt(passphrase []byte, n, r, p int) ([]byte, error) {
+	if len(c.Prv) == 0 {
+		return nil, errors.New("Encrypt: key has no prv")
+	}
+	k := *c // Copy so that Correct does not mutate the receiver.
+	err := k.Correct()
+	if err != nil {
+		return nil, fmt.Errorf("Encrypt: Coz key is not correct; %w", err)
+	}
+
+	e := &KeyEnc{
+		KDF:   KDFScrypt,
+		Salt:  make([]byte, saltSize),
+		N:     n,
+		R:     r,
+		P:     p,
+		AEAD:  AEADXChaCha20Poly1305,
+		Nonce: make([]byte, chacha20poly1305.NonceSizeX),
+	}
+	if _, err = rand.Read(e.Salt); err != nil {
+		return nil, err
+	}
+	if _, err = rand.Read(e.Nonce); err != nil {
+		return nil, err
+	}
+
+	aead, err := e.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	ek := &EncryptedKey{
+		Alg: k.Alg,
+		Enc: e,
+		Now: k.Now,
+		Tag: k.Tag,
+		Rvk: k.Rvk,
+		Tmb: k.Tmb,
+		Typ: k.Typ,
+		Pub: k.Pub,
+	}
+	ad, err := ek.ad()
+	if err != nil {
+		return nil, err
+	}
+	e.Ct = aead.Seal(nil, e.Nonce, k.Prv, ad)
+	return Marshal(ek)
+}
+
+// DecryptKey decrypts a JSON encoded EncryptedKey with the given passphrase
+// and returns the plaintext key.  The decrypted key is checked with Correct.
+func DecryptKey(blob []byte, passphrase []byte) (*Key, error) {
+	err := checkDuplicate(json.NewDecoder(bytes.NewReader(blob)))
+	if err != nil {
+		return nil, err
+	}
+	ek := new(EncryptedKey)
+	err = json.Unmarshal(blob, ek)
+	if err != nil {
+		return nil, err
+	}
+	if ek.Enc == nil {
+		return nil, errors.New("DecryptKey: key is not encrypted")
+	}
+
+	aead, err := ek.Enc.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	ad, err := ek.ad()
+	if err != nil {
+		return nil, err
+	}
+	prv, err := aead.Open(nil, ek.Enc.Nonce, ek.Enc.Ct, ad)
+	if err != nil {
+		return nil, errors.New("DecryptKey: incorrect passphrase or corrupted key")
+	}
+
+	k := &Key{
+		Alg: ek.Alg,
+		Prv: prv,
+		Now: ek.Now,
+		Tag: ek.Tag,
+		Rvk: ek.Rvk,
+		Tmb: ek.Tmb,
+		Typ: ek.Typ,
+		Pub: ek.Pub,
+	}
+	err = k.Correct()
+	if err != nil {
+		return nil, fmt.Errorf("DecryptKey: %w", err)
+	}
+	return k, nil
+}
+
+// ad returns the AEAD additional data, which is the canonical JSON of every
+// cleartext field, `{alg,now,tag,rvk,tmb,typ,pub}`, omitting empty fields.
+func (ek *EncryptedKey) ad() ([]byte, error) {
+	return Marshal(&struct {
+		Alg SEAlg     `json:"alg"`
+		Now Timestamp `json:"now,omitempty"`
+		Tag string    `json:"tag,omitempty"`
+		Rvk Timestamp `json:"rvk,omitempty"`
+		Tmb B64       `json:"tmb"`
+		Typ string    `json:"typ,omitempty"`
+		Pub B64       `json:"pub"`
+	}{ek.Alg, ek.Now, ek.Tag, ek.Rvk, ek.Tmb, ek.Typ, ek.Pub})
+}
+
+// aead derives the wrapping key from passphrase and returns the AEAD cipher.
+func (e *KeyEnc) aead(passphrase []byte) (cipher.AEAD, error) {
+	if e.KDF != KDFScrypt {
+		return nil, fmt.Errorf("KeyEnc: unsupported kdf %q", e.KDF)
+	}
+	if e.AEAD != AEADXChaCha20Poly1305 {
+		return nil, fmt.Errorf("KeyEnc: unsupported aead %q", e.AEAD)
+	}
+	if len(e.Salt) < saltSize {
+		return nil, fmt.Errorf("KeyEnc: salt too short; expected at least %d, given %d", saltSize, len(e.Salt))
+	}
+	if len(e.Nonce) != chacha20poly1305.NonceSizeX {
+		return nil, fmt.Errorf("KeyEnc: incorrect nonce length; expected %d, given %d", chacha20poly1305.NonceSizeX, len(e.Nonce))
+	}
+	// N must be a power of two greater than 1, and r*p must not overflow.
+	if e.N <= 1 || e.N&(e.N-1) != 0 || e.N > scryptMaxN ||
+		e.R < 1 || e.P < 1 || e.R > (1<<10)/e.P {
+		return nil, fmt.Errorf("KeyEnc: invalid scrypt parameters; n %d, r %d, p %d", e.N, e.R, e.P)
+	}
+	wk, err := scrypt.Key(passphrase, e.Salt, e.N, e.R, e.P, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("KeyEnc: %w", err)
+	}
+	return chacha20poly1305.NewX(wk)
+}
+
+// isEncryptedKey reports whether JSON b has an `enc` field.
+func isEncryptedKey(b []byte) bool {
+	return hasField(b, "enc")
+}
@@ -0,0 +1,181 @@
+package coz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// ExampleKey_Encrypt demonstrates encrypting and decrypting a private key.
+// Public fields remain in the clear while `prv` is replaced by `enc`.
+func ExampleKey_Encrypt() {
+	blob, err := GoldenKey.encrypt([]byte("correct horse"), 1<<10, 8, 1)
+	if err != nil {
+		panic(err)
+	}
+
+	ek := new(EncryptedKey)
+	err = json.Unmarshal(blob, ek)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(ek.Alg, ek.Tmb, ek.Enc.KDF, ek.Enc.AEAD)
+
+	k, err := DecryptKey(blob, []byte("correct horse"))
+	if err != nil {
+		panic(err)
+	}
+	b, err := Marshal(k)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", b)
+
+	_, err = DecryptKey(blob, []byte("wrong horse"))
+	fmt.Println(err)
+
+	// Output:
+	// ES256 U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg scrypt XChaCha20-Poly1305
+	// {"alg":"ES256","prv":"bNstg4_H3m3SlROufwRSEgibLrBuRq9114OvdapcpVA","now":1623132000,"tag":"Zami's Majuscule Key.","tmb":"U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg","pub":"2nTOaFVm2QLxmUO_SjgyscVHBtvHEfo2rq65MvgNRjORojq39Haq9rXNxvXxwba_Xj0F5vZibJR3isBdOWbo5g"}
+	// DecryptKey: incorrect passphrase or corrupted key
+}
+
+// ExampleKey_UnmarshalJSON_encrypted demonstrates that an encrypted key errors
+// when unmarshalled as a plaintext key.
+func ExampleKey_UnmarshalJSON_encrypted() {
+	blob, err := GoldenKey.encrypt([]byte("correct horse"), 1<<10, 8, 1)
+	if err != nil {
+		panic(err)
+	}
+
+	k := new(Key)
+	err = json.Unmarshal(blob, k)
+	fmt.Println(errors.Is(err, ErrKeyEncrypted))
+
+	// Output:
+	// true
+}
+
+func TestDecryptKey(t *testing.T) {
+	for _, alg := range []SigAlg{ES224, ES256, ES384, ES512, Ed25519} {
+		k, err := NewSigningKey(alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blob, err := k.encrypt([]byte("pass"), 1<<10, 8, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k2, err := DecryptKey(blob, []byte("pass"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(k.Prv, k2.Prv) || k.String() != k2.String() {
+			t.Fatalf("%s: decrypted key does not match; expected %s, given %s", alg, k, k2)
+		}
+	}
+
+	// Public identity is bound to the ciphertext.  Changing `tmb` must fail.
+	blob, err := GoldenKey.encrypt([]byte("pass"), 1<<10, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek := new(EncryptedKey)
+	err = json.Unmarshal(blob, ek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek.Tmb[0] ^= 1
+	b, err := Marshal(ek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = DecryptKey(b, []byte("pass"))
+	if err == nil {
+		t.Fatal("expected error on modified tmb")
+	}
+
+	// Every cleartext field is bound.  Removing `rvk` of a revoked key, or
+	// changing any other field, must fail.
+	rk := GoldenKey
+	rk.Rvk = 1623132000
+	blob, err = rk.encrypt([]byte("pass"), 1<<10, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, mod := range map[string]func(*EncryptedKey){
+		"rvk": func(e *EncryptedKey) { e.Rvk = 0 },
+		"now": func(e *EncryptedKey) { e.Now++ },
+		"tag": func(e *EncryptedKey) { e.Tag = "other" },
+		"typ": func(e *EncryptedKey) { e.Typ = "other" },
+		"pub": func(e *EncryptedKey) { e.Pub[0] ^= 1 },
+	} {
+		e := new(EncryptedKey)
+		if err = json.Unmarshal(blob, e); err != nil {
+			t.Fatal(err)
+		}
+		mod(e)
+		if b, err = Marshal(e); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = DecryptKey(b, []byte("pass")); err == nil {
+			t.Errorf("expected error on modified %s", name)
+		}
+	}
+	k, err := DecryptKey(blob, []byte("pass"))
+	if err != nil || k.Rvk != rk.Rvk {
+		t.Fatalf("expected revoked key, given %v, %v", k, err)
+	}
+
+	// Short salts are rejected.
+	for _, salt := range []B64{nil, make([]byte, 8)} {
+		e := new(EncryptedKey)
+		if err = json.Unmarshal(blob, e); err != nil {
+			t.Fatal(err)
+		}
+		e.Enc.Salt = salt
+		if b, err = Marshal(e); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = DecryptKey(b, []byte("pass")); err == nil {
+			t.Errorf("expected error on salt length %d", len(salt))
+		}
+	}
+
+	// Invalid or excessive cost parameters must be rejected before running the
+	// KDF.
+	for _, p := range [][3]int{
+		{1 << 30, 8, 1}, // Excessive N.
+		{0, 8, 1},
+		{1, 8, 1},
+		{-1 << 10, 8, 1},
+		{1000, 8, 1}, // Not a power of two.
+		{1 << 10, 0, 1},
+		{1 << 10, 8, 0},
+		{1 << 10, -8, 1},
+		{1 << 10, 8, -1},
+		{1 << 10, 1 << 11, 1},         // Excessive r*p.
+		{1 << 10, 1 << 62, 1 << 2},    // r*p overflows.
+		{1 << 10, -1 << 62, -1 << 62}, // Negative r*p overflows.
+	} {
+		ek.Enc.N, ek.Enc.R, ek.Enc.P = p[0], p[1], p[2]
+		b, err = Marshal(ek)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = DecryptKey(b, []byte("pass"))
+		if err == nil {
+			t.Fatalf("expected error on scrypt parameters %v", p)
+		}
+	}
+
+	// Public keys cannot be encrypted.
+	pub := GoldenKey
+	pub.Prv = nil
+	_, err = pub.Encrypt([]byte("pass"))
+	if err == nil {
+		t.Fatal("expected error on public key")
+	}
+}
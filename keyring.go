@@ -0,0 +1,364 @@
+package coz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeySource is a source of keys for verification.  Lookup returns the key for
+// the given thumbprint or an error if not found.
+type KeySource interface {
+	Lookup(tmb B64) (*Key, error)
+}
+
+// ErrKeyNotFound is returned by KeySource.Lookup when no key has the given
+// thumbprint.
+var ErrKeyNotFound = errors.New("Coz: key not found")
+
+// Keyring is a concurrent safe set of Coz keys indexed by `tmb`, `tag`, and
+// `alg`.  A Keyring may be backed by a directory of JSON key files, one key
+// per file.  Keys are always checked with Correct.
+//
+// Keyring implements KeySource.
+type Keyring struct {
+	mu    sync.RWMutex
+	dir   string            // Empty for memory only keyrings.
+	keys  map[B64s]*Key     // tmb → key
+	files map[B64s]string   // tmb → file name, for directory backed keyrings.
+	tags  map[string][]B64s // tag → tmbs
+	algs  map[SEAlg][]B64s  // alg → tmbs
+	snap  string            // Directory snapshot for polling.
+}
+
+// NewKeyring returns an empty, memory only keyring.
+func NewKeyring() *Keyring {
+	kr := new(Keyring)
+	kr.reset()
+	return kr
+}
+
+// LoadKeyring loads every `*.json` file in dir as a key.  Subsequent Add and
+// Remove calls persist changes to dir.
+func LoadKeyring(dir string) (*Keyring, error) {
+	kr := NewKeyring()
+	kr.dir = dir
+	return kr, kr.Reload()
+}
+
+func (kr *Keyring) reset() {
+	kr.keys = make(map[B64s]*Key)
+	kr.files = make(map[B64s]string)
+	kr.tags = make(map[string][]B64s)
+	kr.algs = make(map[SEAlg][]B64s)
+}
+
+// Reload reloads all keys from the keyring's directory, replacing the
+// in-memory keys.  On error, the keyring is unmodified.  Reload is a no-op for
+// memory only keyrings.
+func (kr *Keyring) Reload() error {
+	if kr.dir == "" {
+		return nil
+	}
+	// The lock is held for the whole reload so that a concurrent Add is not
+	// overwritten by an older snapshot of the directory.
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	snap, err := dirSnapshot(kr.dir)
+	if err != nil {
+		return err
+	}
+	names, err := filepath.Glob(filepath.Join(kr.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	next := NewKeyring()
+	next.dir = kr.dir
+	for _, name := range names {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		k := new(Key)
+		err = json.Unmarshal(b, k) // Key.UnmarshalJSON calls Correct.
+		if err != nil {
+			return fmt.Errorf("Keyring: %s: %w", filepath.Base(name), err)
+		}
+		if _, ok := next.keys[B64s(k.Tmb)]; ok {
+			return fmt.Errorf("Keyring: %s: duplicate key tmb %s", filepath.Base(name), k.Tmb)
+		}
+		next.add(k, filepath.Base(name))
+	}
+
+	kr.keys, kr.files, kr.tags, kr.algs = next.keys, next.files, next.tags, next.algs
+	kr.snap = snap
+	return nil
+}
+
+// add adds k to the indexes.  Caller must hold the lock.
+func (kr *Keyring) add(k *Key, file string) {
+	t := B64s(k.Tmb)
+	kr.keys[t] = k
+	if file != "" {
+		kr.files[t] = file
+	}
+	kr.tags[k.Tag] = append(kr.tags[k.Tag], t)
+	kr.algs[k.Alg] = append(kr.algs[k.Alg], t)
+}
+
+// Add adds or replaces key in the keyring.  The key is copied and checked
+// with Correct.  For directory backed keyrings the key is written atomically
+// to "<tmb>.json".
+func (kr *Keyring) Add(key *Key) error {
+	k := cloneKey(key)
+	err := k.Correct()
+	if err != nil {
+		return fmt.Errorf("Keyring: %w", err)
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	file := ""
+	if kr.dir != "" {
+		file = k.Tmb.String() + ".json"
+		if old, ok := kr.files[B64s(k.Tmb)]; ok {
+			file = old
+		}
+		b, err := Marshal(k)
+		if err != nil {
+			return err
+		}
+		err = writeFileAtomic(filepath.Join(kr.dir, file), b)
+		if err != nil {
+			return err
+		}
+		kr.snap, _ = dirSnapshot(kr.dir)
+	}
+	kr.remove(B64s(k.Tmb))
+	kr.add(k, file)
+	return nil
+}
+
+// Remove removes the key with the given thumbprint.  For directory backed
+// keyrings the key's file is deleted.
+func (kr *Keyring) Remove(tmb B64) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	t := B64s(tmb)
+	if _, ok := kr.keys[t]; !ok {
+		return ErrKeyNotFound
+	}
+	if file, ok := kr.files[t]; ok {
+		err := os.Remove(filepath.Join(kr.dir, file))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		kr.snap, _ = dirSnapshot(kr.dir)
+	}
+	kr.remove(t)
+	return nil
+}
+
+// remove removes t from the indexes.  Caller must hold the lock.
+func (kr *Keyring) remove(t B64s) {
+	k, ok := kr.keys[t]
+	if !ok {
+		return
+	}
+	delete(kr.keys, t)
+	delete(kr.files, t)
+	kr.tags[k.Tag] = slices.DeleteFunc(kr.tags[k.Tag], func(s B64s) bool { return s == t })
+	if len(kr.tags[k.Tag]) == 0 {
+		delete(kr.tags, k.Tag)
+	}
+	kr.algs[k.Alg] = slices.DeleteFunc(kr.algs[k.Alg], func(s B64s) bool { return s == t })
+	if len(kr.algs[k.Alg]) == 0 {
+		delete(kr.algs, k.Alg)
+	}
+}
+
+// Get returns a copy of the key with the given thumbprint.
+func (kr *Keyring) Get(tmb B64) (*Key, bool) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	k, ok := kr.keys[B64s(tmb)]
+	if !ok {
+		return nil, false
+	}
+	return cloneKey(k), true
+}
+
+// Lookup implements KeySource.
+func (kr *Keyring) Lookup(tmb B64) (*Key, error) {
+	k, ok := kr.Get(tmb)
+	if !ok {
+		return nil, fmt.Errorf("%w: tmb %s", ErrKeyNotFound, tmb)
+	}
+	return k, nil
+}
+
+// ByTag returns copies of keys with the given `tag`, ordered by `tmb`.
+func (kr *Keyring) ByTag(tag string) []*Key {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	return kr.list(kr.tags[tag])
+}
+
+// ByAlg returns copies of keys with the given `alg`, ordered by `tmb`.
+func (kr *Keyring) ByAlg(alg SEAlg) []*Key {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	return kr.list(kr.algs[alg])
+}
+
+// List returns copies of all keys, ordered by `tmb`.
+func (kr *Keyring) List() []*Key {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	tmbs := make([]B64s, 0, len(kr.keys))
+	for t := range kr.keys {
+		tmbs = append(tmbs, t)
+	}
+	return kr.list(tmbs)
+}
+
+// list returns sorted copies of the keys for tmbs.  Caller must hold the lock.
+func (kr *Keyring) list(tmbs []B64s) []*Key {
+	tmbs = slices.Clone(tmbs)
+	slices.Sort(tmbs)
+	keys := make([]*Key, len(tmbs))
+	for i, t := range tmbs {
+		keys[i] = cloneKey(kr.keys[t])
+	}
+	return keys
+}
+
+// cloneKey returns a deep copy of k so that, for example, Key.Destroy on a
+// returned key does not zero the keyring's `prv`.
+func cloneKey(k *Key) *Key {
+	k2 := *k
+	k2.Prv = bytes.Clone(k.Prv)
+	k2.Tmb = bytes.Clone(k.Tmb)
+	k2.Pub = bytes.Clone(k.Pub)
+	return &k2
+}
+
+// Len returns the number of keys.
+func (kr *Keyring) Len() int {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	return len(kr.keys)
+}
+
+// VerifyCoz looks up the key by `pay.tmb` and verifies cz with it.  See
+// Key.VerifyCoz.
+func (kr *Keyring) VerifyCoz(cz *Coz) (bool, error) {
+	return VerifyWith(kr, cz)
+}
+
+// VerifyWith looks up the signing key of cz by `pay.tmb` in src and verifies
+// cz.  Contextual cozies lacking `pay.tmb` error.
+func VerifyWith(src KeySource, cz *Coz) (bool, error) {
+	p := new(Pay)
+	err := p.unmarshal(cz.Pay, Limits{})
+	if err != nil {
+		return false, err
+	}
+	if len(p.Tmb) == 0 {
+		return false, errors.New("VerifyWith: pay.tmb is not set")
+	}
+	k, err := src.Lookup(p.Tmb)
+	if err != nil {
+		return false, err
+	}
+	return k.VerifyCoz(cz)
+}
+
+// Watch polls the keyring's directory every interval and reloads on change
+// until ctx is done.  Reload errors are passed to onErr if not nil, and the
+// previously loaded keys are retained.
+func (kr *Keyring) Watch(ctx context.Context, interval time.Duration, onErr func(error)) {
+	if kr.dir == "" {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			snap, err := dirSnapshot(kr.dir)
+			if err == nil {
+				kr.mu.RLock()
+				changed := snap != kr.snap
+				kr.mu.RUnlock()
+				if !changed {
+					continue
+				}
+				err = kr.Reload()
+			}
+			if err != nil && onErr != nil {
+				onErr(err)
+			}
+		}
+	}
+}
+
+// dirSnapshot returns a string of the names, sizes, and modification times of
+// `*.json` files in dir, used for change detection.
+func dirSnapshot(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var s strings.Builder
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&s, "%s %d %d\n", e.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	return s.String(), nil
+}
+
+// writeFileAtomic writes b to a temporary file in the same directory as name,
+// syncs, and renames it to name so that readers never observe a partial file.
+func writeFileAtomic(name string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(name), ".tmp-"+filepath.Base(name)+"-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // No-op after successful rename.
+
+	if err = f.Chmod(0o600); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err = f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
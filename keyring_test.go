@@ -0,0 +1,237 @@
+package coz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func ExampleKeyring() {
+	kr := NewKeyring()
+	err := kr.Add(&GoldenKey)
+	if err != nil {
+		panic(err)
+	}
+
+	cz := new(Coz)
+	err = json.Unmarshal([]byte(GoldenCoz), cz)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(kr.VerifyCoz(cz))
+	fmt.Println(len(kr.ByTag(GoldenKey.Tag)), len(kr.ByAlg(GoldenKey.Alg)))
+
+	err = kr.Remove(GoldenKey.Tmb)
+	if err != nil {
+		panic(err)
+	}
+	_, err = kr.VerifyCoz(cz)
+	fmt.Println(err)
+
+	// Output:
+	// true <nil>
+	// 1 1
+	// Coz: key not found: tmb U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg
+}
+
+func TestLoadKeyring(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "golden.json"), []byte(GoldenKeyString), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "ignored.txt"), []byte("not a key"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kr, err := LoadKeyring(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kr.Len() != 1 {
+		t.Fatalf("expected 1 key, given %d", kr.Len())
+	}
+
+	// Add persists to the directory and a fresh load sees it.
+	k, err := NewSigningKey(Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k.Tag = "device"
+	err = kr.Add(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kr2, err := LoadKeyring(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := kr2.ByTag("device"); len(got) != 1 || got[0].Tmb.String() != k.Tmb.String() {
+		t.Fatalf("expected persisted key; given %v", got)
+	}
+
+	// Remove deletes the golden key's original file.
+	err = kr.Remove(GoldenKey.Tmb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "golden.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected golden.json to be removed; %v", err)
+	}
+
+	// An incorrect key fails loading and leaves the keyring unmodified.
+	bad, err := Marshal(GoldenKeyBadX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "bad.json"), bad, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = kr.Reload(); err == nil {
+		t.Fatal("expected error on incorrect key")
+	}
+	if kr.Len() != 1 {
+		t.Fatalf("expected keyring to be unmodified, given %d keys", kr.Len())
+	}
+}
+
+func TestKeyring_Watch(t *testing.T) {
+	dir := t.TempDir()
+	kr, err := LoadKeyring(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		kr.Watch(ctx, 10*time.Millisecond, nil)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	b, err := Marshal(GoldenKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writeFileAtomic(filepath.Join(dir, "golden.json"), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		if _, ok := kr.Get(GoldenKey.Tmb); ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Watch did not reload new key")
+}
+
+func TestKeyring_concurrent(t *testing.T) {
+	kr := NewKeyring()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			k, err := NewSigningKey(ES256)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if err = kr.Add(k); err != nil {
+				t.Error(err)
+			}
+			kr.List()
+			kr.ByAlg(k.Alg)
+			if err = kr.Remove(k.Tmb); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if kr.Len() != 0 {
+		t.Fatalf("expected empty keyring, given %d", kr.Len())
+	}
+}
+
+// TestKeyring_copy ensures keys are deep copied in and out of the keyring.
+func TestKeyring_copy(t *testing.T) {
+	kr := NewKeyring()
+	k, err := NewSigningKey(ES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prv := k.Prv.String()
+	if err = kr.Add(k); err != nil {
+		t.Fatal(err)
+	}
+	k.Destroy()
+
+	got, ok := kr.Get(k.Tmb)
+	if !ok {
+		t.Fatal("key not found")
+	}
+	got.Destroy()
+	got.Pub[0] ^= 1
+	kr.List()[0].Destroy()
+
+	got, _ = kr.Get(k.Tmb)
+	if got.Prv.String() != prv || got.Correct() != nil {
+		t.Fatal("keyring key was modified through a copy")
+	}
+}
+
+// TestKeyring_reloadAdd ensures Reload does not drop keys added concurrently.
+func TestKeyring_reloadAdd(t *testing.T) {
+	kr, err := LoadKeyring(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				if err := kr.Reload(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}
+	}()
+	var tmbs []B64
+	for i := 0; i < 20; i++ {
+		k, err := NewSigningKey(ES256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = kr.Add(k); err != nil {
+			t.Fatal(err)
+		}
+		tmbs = append(tmbs, k.Tmb)
+	}
+	close(done)
+	wg.Wait()
+	for _, tmb := range tmbs {
+		if _, ok := kr.Get(tmb); !ok {
+			t.Fatalf("key %s dropped by concurrent Reload", tmb)
+		}
+	}
+}
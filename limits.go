@@ -0,0 +1,45 @@
+package coz
+
+import "fmt"
+
+// Limits bounds the resources used when unmarshalling untrusted JSON.  A zero
+// field is unlimited.  Limits are set per call, on Coz.Limits, Pay.Limits,
+// ParseOptions.Limits, or with CanonLimits, and are never a package global.
+//
+// Limits apply where JSON is received.  Functions that re-parse a `pay`
+// already in memory, such as Meta, Key.VerifyCoz, Key.SignPayJSON, and Canon,
+// apply no limits.
+type Limits struct {
+	MaxCozSize   int // Bytes of a `coz`.
+	MaxPaySize   int // Bytes of a `pay`.
+	MaxDepth     int // Nesting depth of objects and arrays.  The top level is 1.
+	MaxFields    int // Fields of any one object.
+	MaxStringLen int // Bytes of any one decoded string, including field names.
+}
+
+// DefaultMaxDepth is the MaxDepth of DefaultLimits.  It also bounds the
+// nesting of keys and encrypted keys.
+const DefaultMaxDepth = 64
+
+// DefaultLimits returns the Limits used when none are given.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxCozSize:   DefaultMaxRecordSize,
+		MaxPaySize:   DefaultMaxRecordSize,
+		MaxDepth:     DefaultMaxDepth,
+		MaxFields:    1024,
+		MaxStringLen: DefaultMaxRecordSize,
+	}
+}
+
+// LimitError is returned when input exceeds Limits.  Limit is the name of the
+// exceeded Limits field.
+type LimitError struct {
+	Limit string
+	Max   int
+}
+
+// Error implements error.
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("Coz: JSON exceeds %s of %d", e.Limit, e.Max)
+}
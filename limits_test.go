@@ -0,0 +1,173 @@
+package coz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func ExampleLimits() {
+	cz := &Coz{Limits: &Limits{MaxDepth: 2}}
+	err := json.Unmarshal([]byte(`{"pay":{"a":{"b":1}},"sig":"AA"}`), cz)
+	fmt.Println(err)
+
+	// Output:
+	// Coz: JSON exceeds MaxDepth of 2
+}
+
+func TestLimits(t *testing.T) {
+	l := Limits{MaxCozSize: 256, MaxPaySize: 64, MaxDepth: 4, MaxFields: 4, MaxStringLen: 16}
+	tests := []struct {
+		name  string
+		in    string
+		limit string
+	}{
+		{"ok", `{"pay":{"a":[{"b":"c"}]},"sig":"AA"}`, ""},
+		{"coz size", `{"pay":{},"sig":"` + strings.Repeat("A", 256) + `"}`, "MaxCozSize"},
+		{"pay size", `{"pay":{"a":"` + strings.Repeat("A", 16) + `","b":"` + strings.Repeat("A", 16) + `","c":"` + strings.Repeat("A", 16) + `"},"sig":"AA"}`, "MaxPaySize"},
+		{"depth", `{"pay":{"a":[[{}]]},"sig":"AA"}`, "MaxDepth"},
+		{"fields", `{"pay":{"a":1,"b":2,"c":3,"d":4,"e":5},"sig":"AA"}`, "MaxFields"},
+		{"string", `{"pay":{"a":"` + strings.Repeat("A", 17) + `"},"sig":"AA"}`, "MaxStringLen"},
+		{"key", `{"pay":{"` + strings.Repeat("A", 17) + `":1},"sig":"AA"}`, "MaxStringLen"},
+	}
+	for _, tt := range tests {
+		cz := &Coz{Limits: &l}
+		err := json.Unmarshal([]byte(tt.in), cz)
+		var le *LimitError
+		if tt.limit == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			if cz.Limits != &l {
+				t.Errorf("%s: Limits not preserved", tt.name)
+			}
+			continue
+		}
+		if !errors.As(err, &le) || le.Limit != tt.limit {
+			t.Errorf("%s: expected %s, given %v", tt.name, tt.limit, err)
+		}
+	}
+
+	// Defaults apply without Limits, and zero Limits are unlimited.
+	deep := `{"pay":{"a":` + strings.Repeat("[", 100) + strings.Repeat("]", 100) + `},"sig":"AA"}`
+	if err := json.Unmarshal([]byte(deep), new(Coz)); err == nil {
+		t.Error("expected DefaultLimits MaxDepth error")
+	}
+	if err := json.Unmarshal([]byte(deep), &Coz{Limits: &Limits{}}); err != nil {
+		t.Error(err)
+	}
+
+	p := &Pay{Limits: &Limits{MaxFields: 1}}
+	if err := json.Unmarshal([]byte(`{"alg":"ES256","typ":"a"}`), p); err == nil {
+		t.Error("expected Pay MaxFields error")
+	}
+	if _, err := CanonLimits(json.RawMessage(`{"a":{"b":{}}}`), Limits{MaxDepth: 2}); err == nil {
+		t.Error("expected Canon MaxDepth error")
+	}
+	if _, err := ParseCoz([]byte(deep), ParseOptions{Limits: &Limits{MaxDepth: 102}}); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestLimits_internal tests that re-parsing a `pay` already in memory applies
+// no limits, while keys remain bounded in depth.
+func TestLimits_internal(t *testing.T) {
+	fields := make(map[string]string)
+	for i := range 2000 {
+		fields[fmt.Sprintf("f%d", i)] = "v"
+	}
+	fields["big"] = strings.Repeat("A", 2*DefaultMaxRecordSize)
+	cz, err := GoldenKey.SignPayRaw(&Pay{Alg: GoldenKey.Alg, Tmb: GoldenKey.Tmb, Struct: fields})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cz.Pay) <= DefaultMaxRecordSize {
+		t.Fatalf("expected pay over the default size, given %d", len(cz.Pay))
+	}
+	if err = json.Unmarshal(cz.Pay, new(Pay)); err == nil {
+		t.Fatal("expected DefaultLimits error for Pay.UnmarshalJSON")
+	}
+
+	v, err := GoldenKey.VerifyCoz(cz)
+	if err != nil || !v {
+		t.Fatalf("VerifyCoz: %v, %v", v, err)
+	}
+	if err = cz.Meta(); err != nil {
+		t.Fatal(err)
+	}
+	if len(cz.Can) != 2003 {
+		t.Fatalf("expected 2003 fields, given %d", len(cz.Can))
+	}
+	if _, err = GoldenKey.SignPayJSON(cz.Pay); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Canon(cz.Pay); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = CanonLimits(cz.Pay, DefaultLimits()); err == nil {
+		t.Fatal("expected CanonLimits error")
+	}
+
+	// Keys are bounded in depth.
+	deep := `{"alg":"ES256","x":` + strings.Repeat("[", DefaultMaxDepth) + strings.Repeat("]", DefaultMaxDepth) + `}`
+	var le *LimitError
+	if err = json.Unmarshal([]byte(deep), new(Key)); !errors.As(err, &le) || le.Limit != "MaxDepth" {
+		t.Fatalf("Key: expected MaxDepth error, given %v", err)
+	}
+	if _, err = DecryptKey([]byte(deep), []byte("pass")); !errors.As(err, &le) || le.Limit != "MaxDepth" {
+		t.Fatalf("DecryptKey: expected MaxDepth error, given %v", err)
+	}
+}
+
+// FuzzLimits ensures pathological input never panics and that input accepted
+// under Limits is within them.
+func FuzzLimits(f *testing.F) {
+	f.Add([]byte(GoldenCoz), 3)
+	f.Add([]byte(`{"pay":`+strings.Repeat(`{"a":`, 50)+`1`+strings.Repeat(`}`, 50)+`}`), 8)
+	f.Add([]byte(strings.Repeat("[", 10000)), 1)
+	f.Add([]byte(`{"pay":{"`+strings.Repeat(`a":1,"`, 100)+`b":1}}`), 4)
+	f.Fuzz(func(t *testing.T, b []byte, n int) {
+		if n <= 0 || n > 64 {
+			return
+		}
+		l := Limits{MaxCozSize: 1 << 12, MaxPaySize: 1 << 11, MaxDepth: n, MaxFields: n, MaxStringLen: n * 8}
+		cz := &Coz{Limits: &l}
+		if json.Unmarshal(b, cz) != nil {
+			return
+		}
+		if len(b) > l.MaxCozSize || len(cz.Pay) > l.MaxPaySize {
+			t.Fatalf("accepted oversized input %q", b)
+		}
+		var v any
+		if err := json.Unmarshal(b, &v); err != nil {
+			t.Fatal(err)
+		}
+		if d := depth(v); d > l.MaxDepth {
+			t.Fatalf("accepted depth %d over %d", d, l.MaxDepth)
+		}
+	})
+}
+
+// depth returns the nesting depth of v with the top level at 1.
+func depth(v any) int {
+	max := 0
+	switch v := v.(type) {
+	case map[string]any:
+		for _, e := range v {
+			if d := depth(e); d > max {
+				max = d
+			}
+		}
+	case []any:
+		for _, e := range v {
+			if d := depth(e); d > max {
+				max = d
+			}
+		}
+	default:
+		return 0
+	}
+	return max + 1
+}
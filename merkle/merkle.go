@@ -0,0 +1,392 @@
+/*
+Package merkle builds RFC 6962 Merkle trees over `czd` values for publishing
+periodic commitments over many cozies.  See
+https://datatracker.ietf.org/doc/html/rfc6962#section-2.1 and RFC 9162 for
+proof verification.
+
+Leaves are `czd` values, hashed as H(0x00 || czd), and interior nodes as
+H(0x01 || left || right), where H is the HshAlg of the batch, e.g. SHA-256 for
+ES256 cozies.
+
+A signed tree head (STH) is itself a coz with `typ` "coz/merkle/sth":
+
+	{"alg":"ES256","now":1623132000,"tmb":"...","typ":"coz/merkle/sth","root":"...","size":2}
+*/
+package merkle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync"
+
+	"github.com/cyphrme/coz"
+)
+
+// STHTyp is the `pay.typ` of a signed tree head.
+const STHTyp = "coz/merkle/sth"
+
+// Domain separation prefixes from RFC 6962.
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// Tree is an append-only Merkle tree over `czd` values.  Tree is safe for
+// concurrent use.
+type Tree struct {
+	mu     sync.RWMutex
+	hsh    coz.HshAlg
+	leaves []coz.B64 // Leaf hashes.
+}
+
+// New returns a tree using hsh with the given czds as leaves.
+func New(hsh coz.HshAlg, czds ...coz.B64) (*Tree, error) {
+	if hsh.Size() == 0 {
+		return nil, fmt.Errorf("merkle: invalid HshAlg %q", hsh)
+	}
+	t := &Tree{hsh: hsh}
+	for _, czd := range czds {
+		if err := t.Append(czd); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Alg returns the tree's hashing algorithm.
+func (t *Tree) Alg() coz.HshAlg {
+	return t.hsh
+}
+
+// Append appends czd as a leaf.  czd must be the size of the tree's HshAlg.
+func (t *Tree) Append(czd coz.B64) error {
+	if len(czd) != t.hsh.Size() {
+		return fmt.Errorf("merkle: czd length %d does not match %s size %d", len(czd), t.hsh, t.hsh.Size())
+	}
+	h, err := leafHash(t.hsh, czd)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.leaves = append(t.leaves, h)
+	return nil
+}
+
+// Size returns the number of leaves.
+func (t *Tree) Size() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.leaves)
+}
+
+// Root returns the root of the full tree.
+func (t *Tree) Root() (coz.B64, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return mth(t.hsh, t.leaves)
+}
+
+// RootAt returns the root of the tree of the first size leaves.
+func (t *Tree) RootAt(size int) (coz.B64, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if size < 0 || size > len(t.leaves) {
+		return nil, fmt.Errorf("merkle: size %d out of range [0, %d]", size, len(t.leaves))
+	}
+	return mth(t.hsh, t.leaves[:size])
+}
+
+// InclusionProof proves that a leaf is included in a tree of a given size.
+type InclusionProof struct {
+	Alg   coz.HshAlg `json:"alg"`
+	Index int        `json:"index"`
+	Size  int        `json:"size"`
+	Path  []coz.B64  `json:"path"`
+}
+
+// InclusionProof returns the proof for leaf index in the tree of the first
+// size leaves.
+func (t *Tree) InclusionProof(index, size int) (*InclusionProof, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if size < 1 || size > len(t.leaves) || index < 0 || index >= size {
+		return nil, fmt.Errorf("merkle: invalid index %d for size %d of %d", index, size, len(t.leaves))
+	}
+	path, err := inclusionPath(t.hsh, index, t.leaves[:size])
+	if err != nil {
+		return nil, err
+	}
+	return &InclusionProof{Alg: t.hsh, Index: index, Size: size, Path: path}, nil
+}
+
+// Verify verifies that czd is leaf Index of the tree of Size with root.
+func (p *InclusionProof) Verify(czd, root coz.B64) error {
+	if p.Index < 0 || p.Index >= p.Size {
+		return fmt.Errorf("merkle: invalid index %d for size %d", p.Index, p.Size)
+	}
+	r, err := leafHash(p.Alg, czd)
+	if err != nil {
+		return err
+	}
+	fn, sn := p.Index, p.Size-1
+	for _, c := range p.Path {
+		if sn == 0 {
+			return errors.New("merkle: inclusion proof too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			if r, err = nodeHash(p.Alg, c, r); err != nil {
+				return err
+			}
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else if r, err = nodeHash(p.Alg, r, c); err != nil {
+			return err
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return errors.New("merkle: inclusion proof too short")
+	}
+	if !bytes.Equal(r, root) {
+		return errors.New("merkle: inclusion proof root mismatch")
+	}
+	return nil
+}
+
+// ConsistencyProof proves that the tree of size Old is a prefix of the tree of
+// size New.
+type ConsistencyProof struct {
+	Alg  coz.HshAlg `json:"alg"`
+	Old  int        `json:"old"`
+	New  int        `json:"new"`
+	Path []coz.B64  `json:"path"`
+}
+
+// ConsistencyProof returns the proof that the tree of the first oldSize leaves
+// is a prefix of the tree of the first newSize leaves.
+func (t *Tree) ConsistencyProof(oldSize, newSize int) (*ConsistencyProof, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if oldSize < 0 || oldSize > newSize || newSize > len(t.leaves) {
+		return nil, fmt.Errorf("merkle: invalid consistency range [%d, %d] of %d", oldSize, newSize, len(t.leaves))
+	}
+	p := &ConsistencyProof{Alg: t.hsh, Old: oldSize, New: newSize, Path: []coz.B64{}}
+	if oldSize == 0 || oldSize == newSize {
+		return p, nil
+	}
+	var err error
+	p.Path, err = subproof(t.hsh, oldSize, t.leaves[:newSize], true)
+	return p, err
+}
+
+// Verify verifies that oldRoot and newRoot are the roots of consistent trees
+// of sizes Old and New.
+func (p *ConsistencyProof) Verify(oldRoot, newRoot coz.B64) error {
+	switch {
+	case p.Old < 0 || p.Old > p.New:
+		return fmt.Errorf("merkle: invalid consistency range [%d, %d]", p.Old, p.New)
+	case p.Old == p.New:
+		if len(p.Path) != 0 || !bytes.Equal(oldRoot, newRoot) {
+			return errors.New("merkle: consistency proof mismatch for equal sizes")
+		}
+		return nil
+	case p.Old == 0: // The empty tree is a prefix of every tree.
+		if len(p.Path) != 0 {
+			return errors.New("merkle: consistency proof must be empty for size 0")
+		}
+		return nil
+	}
+
+	path := p.Path
+	if bits.OnesCount(uint(p.Old)) == 1 { // Old is a power of 2.
+		path = append([]coz.B64{oldRoot}, path...)
+	}
+	if len(path) == 0 {
+		return errors.New("merkle: consistency proof is empty")
+	}
+	fn, sn := p.Old-1, p.New-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := path[0], path[0]
+	var err error
+	for _, c := range path[1:] {
+		if sn == 0 {
+			return errors.New("merkle: consistency proof too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			if fr, err = nodeHash(p.Alg, c, fr); err != nil {
+				return err
+			}
+			if sr, err = nodeHash(p.Alg, c, sr); err != nil {
+				return err
+			}
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else if sr, err = nodeHash(p.Alg, sr, c); err != nil {
+			return err
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return errors.New("merkle: consistency proof too short")
+	}
+	if !bytes.Equal(fr, oldRoot) || !bytes.Equal(sr, newRoot) {
+		return errors.New("merkle: consistency proof root mismatch")
+	}
+	return nil
+}
+
+// TreeHead is the custom `pay` of a signed tree head.
+type TreeHead struct {
+	Root coz.B64 `json:"root"`
+	Size int     `json:"size"`
+}
+
+// SignTreeHead returns a signed tree head coz for the current tree.  key's
+// hashing algorithm must match the tree's.
+func SignTreeHead(key *coz.Key, t *Tree) (*coz.Coz, error) {
+	if key.Alg.Hash() != t.hsh {
+		return nil, fmt.Errorf("merkle: key hash %s does not match tree hash %s", key.Alg.Hash(), t.hsh)
+	}
+	t.mu.RLock()
+	size := len(t.leaves)
+	root, err := mth(t.hsh, t.leaves)
+	t.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	return key.SignPayRaw(&coz.Pay{
+		Alg:    key.Alg,
+		Now:    coz.Now(),
+		Tmb:    key.Tmb,
+		Typ:    STHTyp,
+		Struct: &TreeHead{Root: root, Size: size},
+	})
+}
+
+// VerifyTreeHead verifies a signed tree head with key and returns the tree head
+// and the signed `pay`.
+func VerifyTreeHead(key *coz.Key, cz *coz.Coz) (*TreeHead, *coz.Pay, error) {
+	th := new(TreeHead)
+	p := &coz.Pay{Struct: th}
+	err := json.Unmarshal(cz.Pay, p)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.Typ != STHTyp {
+		return nil, nil, fmt.Errorf("merkle: typ %q is not %q", p.Typ, STHTyp)
+	}
+	v, err := key.VerifyCoz(cz)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !v {
+		return nil, nil, errors.New("merkle: invalid tree head signature")
+	}
+	if th.Size < 0 || len(th.Root) != key.Alg.Hash().Size() {
+		return nil, nil, errors.New("merkle: malformed tree head")
+	}
+	return th, p, nil
+}
+
+func leafHash(h coz.HshAlg, czd coz.B64) (coz.B64, error) {
+	return coz.Hash(h, append([]byte{leafPrefix}, czd...))
+}
+
+func nodeHash(h coz.HshAlg, l, r coz.B64) (coz.B64, error) {
+	b := make([]byte, 0, 1+len(l)+len(r))
+	b = append(b, nodePrefix)
+	b = append(b, l...)
+	return coz.Hash(h, append(b, r...))
+}
+
+// split returns the largest power of 2 less than n.  n must be > 1.
+func split(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+// mth is the Merkle Tree Hash of leaf hashes.
+func mth(h coz.HshAlg, leaves []coz.B64) (coz.B64, error) {
+	switch len(leaves) {
+	case 0:
+		return coz.Hash(h, nil)
+	case 1:
+		return leaves[0], nil
+	}
+	k := split(len(leaves))
+	l, err := mth(h, leaves[:k])
+	if err != nil {
+		return nil, err
+	}
+	r, err := mth(h, leaves[k:])
+	if err != nil {
+		return nil, err
+	}
+	return nodeHash(h, l, r)
+}
+
+// inclusionPath is PATH(m, D[n]) from RFC 6962 2.1.1.
+func inclusionPath(h coz.HshAlg, m int, leaves []coz.B64) ([]coz.B64, error) {
+	if len(leaves) <= 1 {
+		return []coz.B64{}, nil
+	}
+	k := split(len(leaves))
+	var sub, other []coz.B64
+	if m < k {
+		sub, other = leaves[:k], leaves[k:]
+	} else {
+		sub, other, m = leaves[k:], leaves[:k], m-k
+	}
+	path, err := inclusionPath(h, m, sub)
+	if err != nil {
+		return nil, err
+	}
+	o, err := mth(h, other)
+	if err != nil {
+		return nil, err
+	}
+	return append(path, o), nil
+}
+
+// subproof is SUBPROOF(m, D[n], b) from RFC 6962 2.1.2.
+func subproof(h coz.HshAlg, m int, leaves []coz.B64, b bool) ([]coz.B64, error) {
+	n := len(leaves)
+	if m == n {
+		if b {
+			return []coz.B64{}, nil
+		}
+		r, err := mth(h, leaves)
+		return []coz.B64{r}, err
+	}
+	k := split(n)
+	var path []coz.B64
+	var other []coz.B64
+	var err error
+	if m <= k {
+		path, err = subproof(h, m, leaves[:k], b)
+		other = leaves[k:]
+	} else {
+		path, err = subproof(h, m-k, leaves[k:], false)
+		other = leaves[:k]
+	}
+	if err != nil {
+		return nil, err
+	}
+	o, err := mth(h, other)
+	if err != nil {
+		return nil, err
+	}
+	return append(path, o), nil
+}
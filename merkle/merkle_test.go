@@ -0,0 +1,144 @@
+package merkle
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/cyphrme/coz"
+)
+
+// TestMTH uses the RFC 6962 reference test vectors from certificate-transparency.
+func TestMTH(t *testing.T) {
+	data := []string{"", "00", "10", "2021", "3031", "40414243", "5051525354555657", "606162636465666768696a6b6c6d6e6f"}
+	roots := []string{
+		"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+		"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+		"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+		"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+		"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+	}
+	var leaves []coz.B64
+	for i, d := range data {
+		b, _ := hex.DecodeString(d)
+		h, err := leafHash(coz.SHA256, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaves = append(leaves, h)
+		r, err := mth(coz.SHA256, leaves)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(r) != roots[i] {
+			t.Fatalf("size %d: expected root %s, given %x", i+1, roots[i], []byte(r))
+		}
+	}
+}
+
+func czds(t testing.TB, n int) []coz.B64 {
+	c := make([]coz.B64, n)
+	for i := range c {
+		var err error
+		c[i], err = coz.Hash(coz.SHA256, []byte(fmt.Sprint(i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c
+}
+
+func TestProofs(t *testing.T) {
+	const n = 20
+	c := czds(t, n)
+	tr, err := New(coz.SHA256, c...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := make([]coz.B64, n+1)
+	for s := 0; s <= n; s++ {
+		if roots[s], err = tr.RootAt(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for size := 1; size <= n; size++ {
+		for i := 0; i < size; i++ {
+			p, err := tr.InclusionProof(i, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = p.Verify(c[i], roots[size]); err != nil {
+				t.Fatalf("inclusion %d/%d: %v", i, size, err)
+			}
+			if err = p.Verify(c[(i+1)%n], roots[size]); err == nil {
+				t.Fatalf("inclusion %d/%d: wrong leaf verified", i, size)
+			}
+		}
+	}
+
+	for old := 0; old <= n; old++ {
+		for nw := old; nw <= n; nw++ {
+			p, err := tr.ConsistencyProof(old, nw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Round trip through JSON.
+			b, err := json.Marshal(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p = new(ConsistencyProof)
+			if err = json.Unmarshal(b, p); err != nil {
+				t.Fatal(err)
+			}
+			if err = p.Verify(roots[old], roots[nw]); err != nil {
+				t.Fatalf("consistency %d→%d: %v", old, nw, err)
+			}
+			if old > 0 && old < nw {
+				if err = p.Verify(roots[old-1], roots[nw]); err == nil {
+					t.Fatalf("consistency %d→%d: wrong old root verified", old, nw)
+				}
+			}
+		}
+	}
+}
+
+func ExampleSignTreeHead() {
+	k, err := coz.NewKey(coz.SEAlg(coz.ES256))
+	if err != nil {
+		panic(err)
+	}
+	cz, err := k.SignPay(&coz.Pay{Alg: k.Alg, Tmb: k.Tmb, Typ: "example/msg"})
+	if err != nil {
+		panic(err)
+	}
+	if err = cz.Meta(); err != nil {
+		panic(err)
+	}
+
+	tr, err := New(k.Alg.Hash(), cz.Czd)
+	if err != nil {
+		panic(err)
+	}
+	sth, err := SignTreeHead(k, tr)
+	if err != nil {
+		panic(err)
+	}
+	th, _, err := VerifyTreeHead(k, sth)
+	if err != nil {
+		panic(err)
+	}
+	p, err := tr.InclusionProof(0, th.Size)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(th.Size, p.Verify(cz.Czd, th.Root))
+
+	// Output:
+	// 1 <nil>
+}
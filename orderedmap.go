@@ -1,10 +1,14 @@
-// orderedMap is used because encoding/json has no way to preserve the order of
+// Object is used because encoding/json has no way to preserve the order of
 // map keys. See https://github.com/golang/go/issues/27179.
 //
 // Using JSONv2 is the future goal, which solves field order, other issues,
 // and has other best practices.When Go Coz is migrated to JSONv2, as long as
-// JSONv2 provides ordering, orderedMap will be deprecated. See
+// JSONv2 provides ordering, Object will be deprecated. See
 // https://github.com/Cyphrme/Coz/issues/15
+//
+// When built with the tag coz_jsonv2, Canon and duplicate checking use
+// encoding/json/jsontext, which decodes object names in order natively, instead
+// of Object.  See json_v2.go.
 
 // The MIT License (MIT)
 //
@@ -34,49 +38,66 @@ package coz
 import (
 	"bytes"
 	"encoding/json"
+	"iter"
+	"slices"
 	"sort"
 )
 
-type pair struct {
+// Pair is a field of an Object.  See Object.Sort.
+type Pair struct {
 	key   string
 	value any
 }
 
-func (kv *pair) Key() string {
+func (kv *Pair) Key() string {
 	return kv.key
 }
 
-func (kv *pair) Value() any {
+func (kv *Pair) Value() any {
 	return kv.value
 }
 
 type byPair struct {
-	Pairs    []*pair
-	LessFunc func(a *pair, j *pair) bool
+	Pairs    []*Pair
+	LessFunc func(a *Pair, j *Pair) bool
 }
 
 func (a byPair) Len() int           { return len(a.Pairs) }
 func (a byPair) Swap(i, j int)      { a.Pairs[i], a.Pairs[j] = a.Pairs[j], a.Pairs[i] }
 func (a byPair) Less(i, j int) bool { return a.LessFunc(a.Pairs[i], a.Pairs[j]) }
 
-type orderedMap struct {
+// Object is a JSON object that preserves field order, for building and
+// inspecting pays in order.  Nested JSON objects are unmarshalled as Object,
+// arrays as []any, and numbers as float64.  The zero value is an empty object
+// ready to use.
+type Object struct {
 	keys   []string
 	values map[string]any
+
+	// Limits optionally overrides DefaultLimits for UnmarshalJSON.
+	Limits *Limits
 }
 
-func newOrderedMap() *orderedMap {
-	o := orderedMap{}
+// NewObject returns a new, empty Object.
+func NewObject() *Object {
+	o := Object{}
 	o.keys = []string{}
 	o.values = map[string]any{}
 	return &o
 }
 
-func (o *orderedMap) Get(key string) (any, bool) {
+// Get returns the value of key and whether key is present.
+func (o *Object) Get(key string) (any, bool) {
 	val, ok := o.values[key]
 	return val, ok
 }
 
-func (o *orderedMap) Set(key string, value any) {
+// Set sets key to value.  A new key is appended, and an existing key keeps its
+// position.
+func (o *Object) Set(key string, value any) {
+	if o.values == nil {
+		o.values = map[string]any{}
+	}
 	_, ok := o.values[key]
 	if !ok {
 		o.keys = append(o.keys, key)
@@ -84,7 +105,25 @@ func (o *orderedMap) Set(key string, value any) {
 	o.values[key] = value
 }
 
-func (o *orderedMap) Delete(key string) {
+// Insert sets key to value at index at, moving key if already present.  Insert
+// panics if at is out of range, after any existing key is removed, as does
+// slices.Insert.
+func (o *Object) Insert(at int, key string, value any) {
+	o.Delete(key)
+	o.keys = slices.Insert(o.keys, at, key)
+	if o.values == nil {
+		o.values = map[string]any{}
+	}
+	o.values[key] = value
+}
+
+// Len returns the number of fields.
+func (o *Object) Len() int {
+	return len(o.keys)
+}
+
+// Delete removes key, if present.
+func (o *Object) Delete(key string) {
 	// check key is in use
 	_, ok := o.values[key]
 	if !ok {
@@ -101,11 +140,13 @@ func (o *orderedMap) Delete(key string) {
 	delete(o.values, key)
 }
 
-func (o *orderedMap) Keys() []string {
-	return o.keys
+// Keys returns a copy of the keys in order.
+func (o *Object) Keys() []string {
+	return slices.Clone(o.keys)
 }
 
-func (o *orderedMap) Values() []any {
+// Values returns the values in order.
+func (o *Object) Values() []any {
 	v := make([]any, len(o.keys))
 	for i, k := range o.keys {
 		v[i] = o.values[k]
@@ -113,16 +154,39 @@ func (o *orderedMap) Values() []any {
 	return v
 }
 
+// All returns an iterator over the fields in order.  Fields must not be set or
+// deleted during iteration.
+func (o *Object) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for _, k := range o.keys {
+			if !yield(k, o.values[k]) {
+				return
+			}
+		}
+	}
+}
+
+// Canonical returns the JSON of o with fields in canon order.  Fields in canon
+// missing from o are omitted.  If canon is nil, fields are in current order.
+// See CanonicalSpec.
+func (o *Object) Canonical(canon []string) ([]byte, error) {
+	b, err := o.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return CanonicalSpec(b, &CanonSpec{Can: canon})
+}
+
 // SortKeys sorts the map keys using the provided sort func.
-func (o *orderedMap) SortKeys(sortFunc func(keys []string)) {
+func (o *Object) SortKeys(sortFunc func(keys []string)) {
 	sortFunc(o.keys)
 }
 
 // Sort sorts the map using the provided less func.
-func (o *orderedMap) Sort(lessFunc func(a *pair, b *pair) bool) {
-	pairs := make([]*pair, len(o.keys))
+func (o *Object) Sort(lessFunc func(a *Pair, b *Pair) bool) {
+	pairs := make([]*Pair, len(o.keys))
 	for i, key := range o.keys {
-		pairs[i] = &pair{key, o.values[key]}
+		pairs[i] = &Pair{key, o.values[key]}
 	}
 
 	sort.Sort(byPair{pairs, lessFunc})
@@ -132,44 +196,51 @@ func (o *orderedMap) Sort(lessFunc func(a *pair, b *pair) bool) {
 	}
 }
 
-// MarshalJSON must return no duplicates, and should since orderedMap keys are
+// MarshalJSON returns compact JSON, without HTML escaping, as does Marshal.
+// MarshalJSON must return no duplicates, and should since Object keys are
 // unique.
-func (o orderedMap) MarshalJSON() ([]byte, error) {
-	var buf bytes.Buffer
-	buf.WriteByte('{')
-	encoder := json.NewEncoder(&buf)
-	encoder.SetEscapeHTML(false)
+func (o Object) MarshalJSON() ([]byte, error) {
+	b := []byte{'{'}
 	for i, k := range o.keys {
 		if i > 0 {
-			buf.WriteByte(',')
+			b = append(b, ',')
 		}
-		// add key
-		if err := encoder.Encode(k); err != nil {
+		key, err := Marshal(k)
+		if err != nil {
 			return nil, err
 		}
-		buf.WriteByte(':')
-		// add value
-		if err := encoder.Encode(o.values[k]); err != nil {
+		value, err := Marshal(o.values[k])
+		if err != nil {
 			return nil, err
 		}
+		b = append(append(append(b, key...), ':'), value...)
 	}
-	buf.WriteByte('}')
-	return buf.Bytes(), nil
+	return append(b, '}'), nil
 }
 
-func (o *orderedMap) UnmarshalJSON(b []byte) error {
+// UnmarshalJSON unmarshals a JSON object in order, erroring on duplicates.  Any
+// existing fields of o are replaced.  Object.Limits, if set before
+// unmarshalling, bounds the input, otherwise DefaultLimits is used.
+func (o *Object) UnmarshalJSON(b []byte) error {
+	l := DefaultLimits()
+	if o.Limits != nil {
+		l = *o.Limits
+	}
+	return o.unmarshal(b, l)
+}
+
+// unmarshal is UnmarshalJSON bounded by l instead of o.Limits.
+func (o *Object) unmarshal(b []byte, l Limits) error {
 	// Ensure that there were no duplicates fields.  JSON should error on
 	// duplicates. "Last value wins" is bad practice.  See
 	// https://esdiscuss.org/topic/json-duplicate-keys and the Coz docs on
 	// duplicate JSON keys.
-	err := checkDuplicate(json.NewDecoder(bytes.NewReader(b)))
+	err := checkJSON(json.NewDecoder(bytes.NewReader(b)), l, 1)
 	if err != nil {
 		return err
 	}
 
-	if o.values == nil {
-		o.values = map[string]any{}
-	}
+	o.values = map[string]any{}
 	err = json.Unmarshal(b, &o.values)
 	if err != nil {
 		return err
@@ -183,7 +254,7 @@ func (o *orderedMap) UnmarshalJSON(b []byte) error {
 }
 
 // decodeOrderedMap
-func decodeOrderedMap(dec *json.Decoder, o *orderedMap) error {
+func decodeOrderedMap(dec *json.Decoder, o *Object) error {
 	hasKey := make(map[string]bool, len(o.values))
 	for {
 		token, err := dec.Token()
@@ -216,7 +287,7 @@ func decodeOrderedMap(dec *json.Decoder, o *orderedMap) error {
 			switch delim {
 			case '{':
 				if values, ok := o.values[key].(map[string]any); ok {
-					newMap := orderedMap{
+					newMap := Object{
 						keys:   make([]string, 0, len(values)),
 						values: values,
 					}
@@ -224,8 +295,8 @@ func decodeOrderedMap(dec *json.Decoder, o *orderedMap) error {
 						return err
 					}
 					o.values[key] = newMap
-				} else if oldMap, ok := o.values[key].(orderedMap); ok {
-					newMap := orderedMap{
+				} else if oldMap, ok := o.values[key].(Object); ok {
+					newMap := Object{
 						keys:   make([]string, 0, len(oldMap.values)),
 						values: oldMap.values,
 					}
@@ -233,7 +304,7 @@ func decodeOrderedMap(dec *json.Decoder, o *orderedMap) error {
 						return err
 					}
 					o.values[key] = newMap
-				} else if err = decodeOrderedMap(dec, &orderedMap{}); err != nil {
+				} else if err = decodeOrderedMap(dec, &Object{}); err != nil {
 					return err
 				}
 			case '[':
@@ -260,7 +331,7 @@ func decodeSlice(dec *json.Decoder, s []any) error {
 			case '{':
 				if index < len(s) {
 					if values, ok := s[index].(map[string]any); ok {
-						newMap := orderedMap{
+						newMap := Object{
 							keys:   make([]string, 0, len(values)),
 							values: values,
 						}
@@ -268,8 +339,8 @@ func decodeSlice(dec *json.Decoder, s []any) error {
 							return err
 						}
 						s[index] = newMap
-					} else if oldMap, ok := s[index].(orderedMap); ok {
-						newMap := orderedMap{
+					} else if oldMap, ok := s[index].(Object); ok {
+						newMap := Object{
 							keys:   make([]string, 0, len(oldMap.values)),
 							values: oldMap.values,
 						}
@@ -277,10 +348,10 @@ func decodeSlice(dec *json.Decoder, s []any) error {
 							return err
 						}
 						s[index] = newMap
-					} else if err = decodeOrderedMap(dec, &orderedMap{}); err != nil {
+					} else if err = decodeOrderedMap(dec, &Object{}); err != nil {
 						return err
 					}
-				} else if err = decodeOrderedMap(dec, &orderedMap{}); err != nil {
+				} else if err = decodeOrderedMap(dec, &Object{}); err != nil {
 					return err
 				}
 			case '[':
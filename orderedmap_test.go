@@ -25,6 +25,8 @@ package coz
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
 	"sort"
 	"strings"
@@ -32,7 +34,7 @@ import (
 )
 
 func TestOrderedMap(t *testing.T) {
-	o := newOrderedMap()
+	o := NewObject()
 	o.Set("number", 3) // number
 	v, _ := o.Get("number")
 	if v.(int) != 3 {
@@ -103,7 +105,7 @@ func TestOrderedMap(t *testing.T) {
 }
 
 func TestOrderedMapDelete(t *testing.T) {
-	o := newOrderedMap()
+	o := NewObject()
 	o.Set("strings", "stringValue")
 	o.Delete("strings")
 	o.Delete("not a key being used")
@@ -114,7 +116,7 @@ func TestOrderedMapDelete(t *testing.T) {
 }
 
 func TestBlankMarshalJSON(t *testing.T) {
-	o := newOrderedMap()
+	o := NewObject()
 	// blank map
 	b, err := json.Marshal(o)
 	if err != nil {
@@ -138,7 +140,7 @@ func TestBlankMarshalJSON(t *testing.T) {
 }
 
 func TestMarshalJSON(t *testing.T) {
-	o := newOrderedMap()
+	o := NewObject()
 	o.Set("number", 3)                    // number
 	o.Set("string", "x")                  // string
 	o.Set("specialstring", "\\.<>[]{}_-") // string
@@ -152,7 +154,7 @@ func TestMarshalJSON(t *testing.T) {
 		1,
 	})
 
-	v := newOrderedMap()
+	v := NewObject()
 	v.Set("e", 1)
 	v.Set("a", 2)
 	o.Set("orderedmap", v)
@@ -186,7 +188,7 @@ func TestMarshalJSON(t *testing.T) {
 }
 
 func TestMarshalJSONNoEscapeHTML(t *testing.T) {
-	o := newOrderedMap()
+	o := NewObject()
 	// string special characters
 	o.Set("specialstring", "\\.<>[]{}_-")
 	// convert to json
@@ -203,7 +205,7 @@ func TestMarshalJSONNoEscapeHTML(t *testing.T) {
 
 func TestMarshalJSONNoEscapeHTMLRecursive(t *testing.T) {
 	src := `{"x":"<>","y":[{"z":["<>"]}]}`
-	o := newOrderedMap()
+	o := NewObject()
 	err := json.Unmarshal([]byte(src), &o)
 	if err != nil {
 		t.Error("JSON Unmarshal error with special chars", err)
@@ -246,7 +248,7 @@ func TestUnmarshalJSON(t *testing.T) {
   ],
   "should not break with { character in key": 1
 }`
-	o := newOrderedMap()
+	o := NewObject()
 	err := json.Unmarshal([]byte(s), &o)
 	if err != nil {
 		t.Error("JSON Unmarshal error", err)
@@ -282,7 +284,7 @@ func TestUnmarshalJSON(t *testing.T) {
 	if !ok {
 		t.Error("Missing key for nested map 1 deep")
 	}
-	v := vi.(orderedMap) // panics if not correct type
+	v := vi.(Object) // panics if not correct type
 	k = v.Keys()
 	for i := range k {
 		if k[i] != expectedKeys[i] {
@@ -297,7 +299,7 @@ func TestUnmarshalJSON(t *testing.T) {
 	if !ok {
 		t.Error("Missing key for nested map 2 deep")
 	}
-	v = vi.(orderedMap) // panics if not correct type
+	v = vi.(Object) // panics if not correct type
 	k = v.Keys()
 	for i := range k {
 		if k[i] != expectedKeys[i] {
@@ -315,7 +317,7 @@ func TestUnmarshalJSON(t *testing.T) {
 		t.Error("Missing key for multitype array")
 	}
 	vslice := vislice.([]any) // panics if not correct type
-	vmap := vslice[2].(orderedMap)
+	vmap := vslice[2].(Object)
 	k = vmap.Keys()
 	for i := range k {
 		if k[i] != expectedKeys[i] {
@@ -327,7 +329,7 @@ func TestUnmarshalJSON(t *testing.T) {
 	vslice = vislice.([]any) // panics if not correct type
 	expectedKeys = []string{"inner"}
 	vinnerslice := vslice[3].([]any)
-	vinnermap := vinnerslice[0].(orderedMap)
+	vinnermap := vinnerslice[0].(Object)
 	k = vinnermap.Keys()
 	for i := range k {
 		if k[i] != expectedKeys[i] {
@@ -336,7 +338,7 @@ func TestUnmarshalJSON(t *testing.T) {
 	}
 }
 
-// TestOrderedMapUnmarshalJSONDuplicate tests that orderedMap errors on
+// TestOrderedMapUnmarshalJSONDuplicate tests that Object errors on
 // duplicate JSON fields.
 func TestOrderedMapUnmarshalJSONDuplicate(t *testing.T) {
 	s := `{
@@ -346,16 +348,16 @@ func TestOrderedMapUnmarshalJSONDuplicate(t *testing.T) {
 		"d": {"x":1},
 		"b": [{"x":[]}]
 	}`
-	o := newOrderedMap()
+	o := NewObject()
 	err := json.Unmarshal([]byte(s), &o)
 	if err == nil {
-		t.Errorf("orderedMap unmarshal did not error on duplicate key")
+		t.Errorf("Object unmarshal did not error on duplicate key")
 	}
 }
 
 func TestUnmarshalJSONSpecialChars(t *testing.T) {
 	s := `{ " \u0041\n\r\t\\\\\\\\\\\\ "  : { "\\\\\\" : "\\\\\"\\" }, "\\":  " \\\\ test ", "\n": "\r" }`
-	o := newOrderedMap()
+	o := NewObject()
 	err := json.Unmarshal([]byte(s), &o)
 	if err != nil {
 		t.Error("JSON Unmarshal error with special chars", err)
@@ -401,7 +403,7 @@ func TestUnmarshalJSONArrayOfMaps(t *testing.T) {
   ]
 }
 `
-	o := newOrderedMap()
+	o := NewObject()
 	err := json.Unmarshal([]byte(s), &o)
 	if err != nil {
 		t.Error("JSON Unmarshal error", err)
@@ -430,7 +432,7 @@ func TestUnmarshalJSONArrayOfMaps(t *testing.T) {
 	}
 	vs := vi.([]any)
 	for _, vInterface := range vs {
-		v := vInterface.(orderedMap)
+		v := vInterface.(Object)
 		k = v.Keys()
 		for i := range k {
 			if k[i] != expectedKeys[i] {
@@ -442,7 +444,7 @@ func TestUnmarshalJSONArrayOfMaps(t *testing.T) {
 
 func TestUnmarshalJSONStruct(t *testing.T) {
 	var v struct {
-		Data *orderedMap `json:"data"`
+		Data *Object `json:"data"`
 	}
 
 	err := json.Unmarshal([]byte(`{ "data": { "x": 1 } }`), &v)
@@ -466,7 +468,7 @@ func TestOrderedMap_SortKeys(t *testing.T) {
   "c": 3
 }
 `
-	o := newOrderedMap()
+	o := NewObject()
 	json.Unmarshal([]byte(s), &o)
 
 	o.SortKeys(sort.Strings)
@@ -493,9 +495,9 @@ func TestOrderedMap_Sort(t *testing.T) {
   "c": 3
 }
 `
-	o := newOrderedMap()
+	o := NewObject()
 	json.Unmarshal([]byte(s), &o)
-	o.Sort(func(a *pair, b *pair) bool {
+	o.Sort(func(a *Pair, b *Pair) bool {
 		return a.value.(float64) > b.value.(float64)
 	})
 
@@ -517,7 +519,7 @@ func TestOrderedMap_Sort(t *testing.T) {
 func TestOrderedMap_empty_array(t *testing.T) {
 	srcStr := `{"x":[]}`
 	src := []byte(srcStr)
-	om := newOrderedMap()
+	om := NewObject()
 	json.Unmarshal(src, om)
 	bs, _ := json.Marshal(om)
 	marshalledStr := string(bs)
@@ -534,7 +536,7 @@ func TestOrderedMap_empty_array(t *testing.T) {
 func TestOrderedMap_empty_map(t *testing.T) {
 	srcStr := `{"x":{}}`
 	src := []byte(srcStr)
-	om := newOrderedMap()
+	om := NewObject()
 	json.Unmarshal(src, om)
 	bs, _ := json.Marshal(om)
 	marshalledStr := string(bs)
@@ -544,3 +546,241 @@ func TestOrderedMap_empty_map(t *testing.T) {
 		t.Error("Got", marshalledStr)
 	}
 }
+
+func ExampleObject() {
+	var o Object
+	o.Set("typ", "cyphr.me/msg/create")
+	o.Set("msg", "hello")
+	o.Insert(0, "alg", "ES256")
+	for k, v := range o.All() {
+		fmt.Println(k, v)
+	}
+
+	b, err := o.Canonical([]string{"msg", "alg"})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", b)
+
+	// Output:
+	// alg ES256
+	// typ cyphr.me/msg/create
+	// msg hello
+	// {"msg":"hello","alg":"ES256"}
+}
+
+func ExamplePay_Object() {
+	p := new(Pay)
+	err := json.Unmarshal([]byte(GoldenPay), p)
+	if err != nil {
+		panic(err)
+	}
+	o, err := p.Object()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(o.Keys())
+	fmt.Println(o.Get("msg")) // Custom field without Struct.
+
+	// Output:
+	// [alg now tmb typ msg]
+	// Coz is a cryptographic JSON messaging specification. true
+}
+
+func TestObjectInsert(t *testing.T) {
+	o := NewObject()
+	o.Set("a", 1)
+	o.Set("b", 2)
+	o.Insert(1, "c", 3)
+	o.Insert(0, "b", 4) // Moves b.
+	o.Insert(o.Len(), "d", 5)
+	if k := o.Keys(); !reflect.DeepEqual(k, []string{"b", "a", "c", "d"}) {
+		t.Fatalf("given %v", k)
+	}
+	if v, _ := o.Get("b"); v != 4 {
+		t.Fatalf("given %v", v)
+	}
+
+	o.Keys()[0] = "z" // Keys is a copy.
+	if o.Keys()[0] != "b" {
+		t.Fatal("Keys modified the object")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for out of range")
+		}
+	}()
+	o.Insert(5, "e", 6)
+}
+
+func TestObjectAll(t *testing.T) {
+	o := NewObject()
+	err := json.Unmarshal([]byte(`{"z":1,"a":{"y":2,"b":3}}`), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for k := range o.All() {
+		keys = append(keys, k)
+		break // Early stop.
+	}
+	if !reflect.DeepEqual(keys, []string{"z"}) {
+		t.Fatalf("given %v", keys)
+	}
+	a, _ := o.Get("a")
+	inner := a.(Object)
+	if k := inner.Keys(); !reflect.DeepEqual(k, []string{"y", "b"}) {
+		t.Fatalf("given %v", k)
+	}
+}
+
+func TestObjectMarshalJSON(t *testing.T) {
+	o := NewObject()
+	err := json.Unmarshal([]byte(`{ "b" : 2, "a" : { "c" : [ 1, "<" ] } }`), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := o.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"b":2,"a":{"c":[1,"<"]}}` {
+		t.Fatalf("given %s", b)
+	}
+
+	// Unmarshal replaces existing fields.
+	o = NewObject()
+	o.Set("a", 1)
+	if err = json.Unmarshal([]byte(`{"b":2}`), o); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := o.Get("a"); ok {
+		t.Fatal("expected a to be replaced")
+	}
+	o.Set("a", 3)
+	b, err = o.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"b":2,"a":3}` {
+		t.Fatalf("given %s", b)
+	}
+}
+
+func TestObjectCanonical(t *testing.T) {
+	o := NewObject()
+	err := json.Unmarshal([]byte(`{"c":"<&>","a":[1,2],"b":null}`), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		canon []string
+		want  string
+	}{
+		{nil, `{"c":"<&>","a":[1,2],"b":null}`},
+		{[]string{"a", "b", "x"}, `{"a":[1,2],"b":null}`},
+		{[]string{}, `{}`},
+	} {
+		b, err := o.Canonical(tt.canon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.want {
+			t.Errorf("%v: given %s, want %s", tt.canon, b, tt.want)
+		}
+	}
+
+	var le *LimitError
+	o = &Object{Limits: &Limits{MaxFields: 1}}
+	if err = json.Unmarshal([]byte(`{"a":1,"b":2}`), o); !errors.As(err, &le) {
+		t.Fatalf("expected *LimitError, given %v", err)
+	}
+}
+
+func TestPayObject(t *testing.T) {
+	// Without UnmarshalJSON, the pay is marshalled.
+	p := &Pay{Alg: SEAlg(ES256), Typ: "a", Struct: &struct {
+		Msg string `json:"msg"`
+	}{"hi"}}
+	o, err := p.Object()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k := o.Keys(); !reflect.DeepEqual(k, []string{"alg", "typ", "msg"}) {
+		t.Fatalf("given %v", k)
+	}
+
+	p = new(Pay)
+	b := []byte(`{"typ":"a","n":1.5}`)
+	if err = json.Unmarshal(b, p); err != nil {
+		t.Fatal(err)
+	}
+	b[2] = 'x' // Object must not alias the input.
+	o, err = p.Object()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := o.Get("n"); !ok || v != 1.5 {
+		t.Fatalf("given %v", v)
+	}
+	if _, ok := o.Get("typ"); !ok {
+		t.Fatal("Object aliased the input")
+	}
+
+	// Object reflects changes to p.  Custom fields precede `~`.
+	if err = json.Unmarshal([]byte(`{"~":{"a":1},"n":1.5,"typ":"a"}`), p); err != nil {
+		t.Fatal(err)
+	}
+	p.Typ = "changed"
+	p.Alg = SEAlg(ES256)
+	o, err = p.Object()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k := o.Keys(); !reflect.DeepEqual(k, []string{"alg", "typ", "n", "~"}) {
+		t.Fatalf("given %v", k)
+	}
+	if v, _ := o.Get("typ"); v != "changed" {
+		t.Fatalf("expected changed typ, given %v", v)
+	}
+	p.Typ = ""
+	o, err = p.Object()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := o.Get("typ"); ok {
+		t.Fatal("expected no typ")
+	}
+
+	// With Struct, custom fields are only from Struct.
+	p = &Pay{Struct: &struct {
+		Msg string `json:"msg"`
+	}{}}
+	if err = json.Unmarshal([]byte(`{"msg":"hi","n":1}`), p); err != nil {
+		t.Fatal(err)
+	}
+	o, err = p.Object()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k := o.Keys(); !reflect.DeepEqual(k, []string{"msg"}) {
+		t.Fatalf("given %v", k)
+	}
+
+	// Parsed from Meta includes custom fields, as does UnmarshalJSON.
+	cz := new(Coz)
+	if err = json.Unmarshal([]byte(GoldenCoz), cz); err != nil {
+		t.Fatal(err)
+	}
+	if err = cz.Meta(); err != nil {
+		t.Fatal(err)
+	}
+	o, err = cz.Parsed.Object()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k := o.Keys(); !reflect.DeepEqual(k, []string{"alg", "now", "tmb", "typ", "msg"}) {
+		t.Fatalf("given %v", k)
+	}
+}
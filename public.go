@@ -0,0 +1,157 @@
+package coz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPrvPresent is returned when `prv` is found where only public keys are
+// permitted.
+var ErrPrvPresent = errors.New("Coz: prv present in public key")
+
+// Public returns a copy of the key with only the public fields [`alg`, `now`,
+// `tag`, `rvk`, `tmb`, `typ`, `pub`].  Use Public before sharing a key.
+func (c *Key) Public() *Key {
+	return &Key{
+		Alg: c.Alg,
+		Now: c.Now,
+		Tag: c.Tag,
+		Rvk: c.Rvk,
+		Tmb: append(B64(nil), c.Tmb...),
+		Typ: c.Typ,
+		Pub: append(B64(nil), c.Pub...),
+	}
+}
+
+// PublicKey is a Coz key that has no `prv` field, so it cannot leak private
+// material when marshaled.  Unmarshaling a PublicKey errors with ErrPrvPresent
+// if `prv` is given.  Field order matches Key.
+type PublicKey struct {
+	Alg SEAlg     `json:"alg,omitempty"`
+	Now Timestamp `json:"now,omitempty"`
+	Tag string    `json:"tag,omitempty"`
+	Rvk Timestamp `json:"rvk,omitempty"`
+	Tmb B64       `json:"tmb,omitempty"`
+	Typ string    `json:"typ,omitempty"`
+	Pub B64       `json:"pub,omitempty"`
+}
+
+// PublicKey returns the PublicKey of c.
+func (c *Key) PublicKey() PublicKey {
+	return *c.Public().publicKey()
+}
+
+// publicKey converts a Key with no `prv` to *PublicKey.
+func (c *Key) publicKey() *PublicKey {
+	return &PublicKey{
+		Alg: c.Alg,
+		Now: c.Now,
+		Tag: c.Tag,
+		Rvk: c.Rvk,
+		Tmb: c.Tmb,
+		Typ: c.Typ,
+		Pub: c.Pub,
+	}
+}
+
+// Key returns p as a *Key.
+func (p PublicKey) Key() *Key {
+	return &Key{
+		Alg: p.Alg,
+		Now: p.Now,
+		Tag: p.Tag,
+		Rvk: p.Rvk,
+		Tmb: p.Tmb,
+		Typ: p.Typ,
+		Pub: p.Pub,
+	}
+}
+
+// String implements fmt.Stringer. Returns empty on error.
+func (p PublicKey) String() string {
+	b, err := Marshal(p)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// UnmarshalJSON errors with ErrPrvPresent if `prv` is given.  Like
+// Key.UnmarshalJSON, the key is checked with Correct and `tmb` is always
+// populated.
+func (p *PublicKey) UnmarshalJSON(b []byte) error {
+	if hasField(b, "prv") {
+		return ErrPrvPresent
+	}
+	k := new(Key)
+	err := json.Unmarshal(b, k)
+	if err != nil {
+		return err
+	}
+	*p = *k.publicKey()
+	return nil
+}
+
+// CheckNoPrv marshals v and returns ErrPrvPresent if a `prv` field appears
+// at any depth.  CheckNoPrv is intended for tests asserting that values
+// destined for API responses, logs, or storage contain no private keys.
+func CheckNoPrv(v any) error {
+	b, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	return checkNoPrv(json.NewDecoder(bytes.NewReader(b)))
+}
+
+// checkNoPrv walks JSON tokens like checkDuplicate.
+func checkNoPrv(d *json.Decoder) error {
+	t, err := d.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := t.(json.Delim)
+	if !ok {
+		return nil
+	}
+	switch delim {
+	case '{':
+		for d.More() {
+			t, err := d.Token()
+			if err != nil {
+				return err
+			}
+			if strings.EqualFold(t.(string), "prv") {
+				return fmt.Errorf("%w: found field %q", ErrPrvPresent, t)
+			}
+			if err = checkNoPrv(d); err != nil {
+				return err
+			}
+		}
+	case '[':
+		for d.More() {
+			if err = checkNoPrv(d); err != nil {
+				return err
+			}
+		}
+	}
+	_, err = d.Token() // consume trailing } or ]
+	return err
+}
+
+// hasField reports whether JSON object b has the top level field name.  Like
+// encoding/json unmarshalling into a struct, name is case-insensitive.
+func hasField(b []byte, name string) bool {
+	var m map[string]json.RawMessage
+	if json.Unmarshal(b, &m) != nil {
+		return false
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package coz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func ExampleKey_Public() {
+	fmt.Println(CheckNoPrv(GoldenKey) != nil)
+	fmt.Println(CheckNoPrv(GoldenKey.Public()))
+	b, err := Marshal(GoldenKey.Public())
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", b)
+
+	// Output:
+	// true
+	// <nil>
+	// {"alg":"ES256","now":1623132000,"tag":"Zami's Majuscule Key.","tmb":"U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg","pub":"2nTOaFVm2QLxmUO_SjgyscVHBtvHEfo2rq65MvgNRjORojq39Haq9rXNxvXxwba_Xj0F5vZibJR3isBdOWbo5g"}
+}
+
+// ExamplePublicKey_UnmarshalJSON demonstrates that PublicKey rejects `prv`.
+func ExamplePublicKey_UnmarshalJSON() {
+	pk := new(PublicKey)
+	err := json.Unmarshal([]byte(GoldenKeyString), pk)
+	fmt.Println(err)
+
+	b, err := Marshal(GoldenKey.Public())
+	if err != nil {
+		panic(err)
+	}
+	err = json.Unmarshal(b, pk)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(pk)
+
+	// Output:
+	// Coz: prv present in public key
+	// {"alg":"ES256","now":1623132000,"tag":"Zami's Majuscule Key.","tmb":"U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg","pub":"2nTOaFVm2QLxmUO_SjgyscVHBtvHEfo2rq65MvgNRjORojq39Haq9rXNxvXxwba_Xj0F5vZibJR3isBdOWbo5g"}
+}
+
+func TestCheckNoPrv(t *testing.T) {
+	// Nested keys, e.g. a verbose coz or an API response, are found.
+	cz := new(Coz)
+	err := json.Unmarshal([]byte(GoldenCozWKey), cz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type Response struct {
+		Cozies []*Coz `json:"cozies"`
+	}
+	err = CheckNoPrv(Response{Cozies: []*Coz{cz}})
+	if !errors.Is(err, ErrPrvPresent) {
+		t.Fatalf("expected ErrPrvPresent; given %v", err)
+	}
+
+	cz.Key = cz.Key.Public()
+	err = CheckNoPrv(Response{Cozies: []*Coz{cz}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Public and PublicKey round trip.
+	pk := GoldenKey.PublicKey()
+	if pk.Key().String() != GoldenKey.Public().String() {
+		t.Fatalf("PublicKey mismatch; expected %s, given %s", GoldenKey.Public(), pk.Key())
+	}
+	if err = CheckNoPrv(pk); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestPublicKey_UnmarshalJSON_case ensures `prv` in any case is rejected, as
+// Key unmarshalling is case-insensitive.
+func TestPublicKey_UnmarshalJSON_case(t *testing.T) {
+	for _, name := range []string{"prv", "Prv", "PRV", "pRv"} {
+		b := []byte(`{"alg":"ES256","pub":"` + GoldenKey.Pub.String() + `","` + name + `":"` + GoldenKey.Prv.String() + `"}`)
+		err := json.Unmarshal(b, new(PublicKey))
+		if !errors.Is(err, ErrPrvPresent) {
+			t.Errorf("%s: expected ErrPrvPresent; given %v", name, err)
+		}
+		err = checkNoPrv(json.NewDecoder(bytes.NewReader(b)))
+		if !errors.Is(err, ErrPrvPresent) {
+			t.Errorf("%s: checkNoPrv expected ErrPrvPresent; given %v", name, err)
+		}
+	}
+}
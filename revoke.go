@@ -0,0 +1,271 @@
+package coz
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RevocationStore records key revocations from self-revoke cozies.  See the
+// Coz docs on Revoke: systems must immediately mark the associated key as
+// revoked upon receiving a valid self-revoke.
+type RevocationStore interface {
+	// ApplyRevoke verifies the self-revoke cz with key, records `tmb → rvk`,
+	// and sets key.Rvk.  See CheckRevoke.
+	ApplyRevoke(cz *Coz, key *Key) error
+
+	// Rvk returns the recorded `rvk` for tmb, if any.
+	Rvk(tmb B64) (rvk Timestamp, ok bool)
+
+	// IsRevokedAt reports whether the key tmb was revoked at time t, that is,
+	// if `rvk` is recorded and t >= `rvk`.
+	IsRevokedAt(tmb B64, t Timestamp) bool
+}
+
+// CheckRevoke checks that cz is a valid self-revoke for key and returns `rvk`.
+// CheckRevoke errors if:
+//
+//  1. `pay` is larger than RVK_MAX_SIZE (if RVK_MAX_SIZE is not 0).
+//  2. `pay.rvk` is not a valid revoke.  See Pay.IsRevoke.
+//  3. `pay.tmb` is not set or does not match key.
+//  4. The signature does not verify with key.  See Key.VerifyCoz.
+func CheckRevoke(cz *Coz, key *Key) (rvk Timestamp, err error) {
+	if RVK_MAX_SIZE > 0 && len(cz.Pay) > RVK_MAX_SIZE {
+		return 0, fmt.Errorf("CheckRevoke: revoke message size %d exceeds RVK_MAX_SIZE %d", len(cz.Pay), RVK_MAX_SIZE)
+	}
+	p := new(Pay)
+	err = p.unmarshal(cz.Pay, Limits{})
+	if err != nil {
+		return 0, err
+	}
+	if !p.IsRevoke() {
+		return 0, errors.New("CheckRevoke: coz is not a revoke")
+	}
+	if len(p.Tmb) == 0 {
+		return 0, errors.New("CheckRevoke: pay.tmb must be set for self-revoke")
+	}
+	valid, err := key.VerifyCoz(cz)
+	if err != nil {
+		return 0, fmt.Errorf("CheckRevoke: %w", err)
+	}
+	if !valid {
+		return 0, errors.New("CheckRevoke: invalid signature")
+	}
+	return p.Rvk, nil
+}
+
+// MemRevocationStore is a concurrent safe, in-memory RevocationStore.
+type MemRevocationStore struct {
+	mu   sync.RWMutex
+	rvks map[B64s]Timestamp
+}
+
+// NewMemRevocationStore returns an empty MemRevocationStore.
+func NewMemRevocationStore() *MemRevocationStore {
+	return &MemRevocationStore{rvks: make(map[B64s]Timestamp)}
+}
+
+// ApplyRevoke implements RevocationStore.  If a key is revoked multiple times,
+// the earliest `rvk` is retained.
+func (s *MemRevocationStore) ApplyRevoke(cz *Coz, key *Key) error {
+	rvk, err := CheckRevoke(cz, key)
+	if err != nil {
+		return err
+	}
+	key.Rvk = s.record(key.Tmb, rvk)
+	return nil
+}
+
+// record records rvk for tmb, retaining the earliest, and returns the
+// recorded value.
+func (s *MemRevocationStore) record(tmb B64, rvk Timestamp) Timestamp {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.rvks[B64s(tmb)]; ok && old <= rvk {
+		return old
+	}
+	s.rvks[B64s(tmb)] = rvk
+	return rvk
+}
+
+// Rvk implements RevocationStore.
+func (s *MemRevocationStore) Rvk(tmb B64) (rvk Timestamp, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rvk, ok = s.rvks[B64s(tmb)]
+	return rvk, ok
+}
+
+// IsRevokedAt implements RevocationStore.
+func (s *MemRevocationStore) IsRevokedAt(tmb B64, t Timestamp) bool {
+	rvk, ok := s.Rvk(tmb)
+	return ok && t >= rvk
+}
+
+// revokeRecord is a line in a FileRevocationStore.
+type revokeRecord struct {
+	Key *Key `json:"key"`
+	Coz *Coz `json:"coz"`
+}
+
+// FileRevocationStore is a RevocationStore persisted to an append-only file.
+// Each line is a JSON object `{"key":<public key>,"coz":<revoke coz>}`.  On
+// open, every record is re-verified so that a modified file is detected.
+type FileRevocationStore struct {
+	mem  *MemRevocationStore
+	mu   sync.Mutex // Serializes writes to f.
+	f    *os.File
+	name string
+}
+
+// OpenFileRevocationStore opens or creates the append-only file name and
+// loads and verifies existing records.
+func OpenFileRevocationStore(name string) (*FileRevocationStore, error) {
+	s := &FileRevocationStore{mem: NewMemRevocationStore(), name: name}
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		r := new(revokeRecord)
+		err = json.Unmarshal(sc.Bytes(), r)
+		if err == nil && (r.Key == nil || r.Coz == nil) {
+			err = errors.New("missing key or coz")
+		}
+		if err == nil {
+			err = s.mem.ApplyRevoke(r.Coz, r.Key)
+		}
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("FileRevocationStore: %s line %d: %w", name, line, err)
+		}
+	}
+	if err = sc.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	s.f = f
+	return s, nil
+}
+
+// ApplyRevoke implements RevocationStore.  The revoke is appended and synced
+// to the file before it is recorded in memory.
+func (s *FileRevocationStore) ApplyRevoke(cz *Coz, key *Key) error {
+	rvk, err := CheckRevoke(cz, key)
+	if err != nil {
+		return err
+	}
+	b, err := Marshal(&revokeRecord{Key: key.Public(), Coz: &Coz{Pay: cz.Pay, Sig: cz.Sig}})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return errors.New("FileRevocationStore: store is closed")
+	}
+	if _, err = s.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	if err = s.f.Sync(); err != nil {
+		return err
+	}
+	key.Rvk = s.mem.record(key.Tmb, rvk)
+	return nil
+}
+
+// Rvk implements RevocationStore.
+func (s *FileRevocationStore) Rvk(tmb B64) (rvk Timestamp, ok bool) {
+	return s.mem.Rvk(tmb)
+}
+
+// IsRevokedAt implements RevocationStore.
+func (s *FileRevocationStore) IsRevokedAt(tmb B64, t Timestamp) bool {
+	return s.mem.IsRevokedAt(tmb, t)
+}
+
+// Close closes the underlying file.
+func (s *FileRevocationStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+// RevokePolicy selects how VerifyCozAt treats cozies signed by revoked keys.
+type RevokePolicy int
+
+const (
+	// RevokeStrict rejects every coz from a revoked key.
+	RevokeStrict RevokePolicy = iota
+	// RevokeHistorical accepts cozies from a revoked key with `pay.now` before
+	// `key.rvk`.  Cozies lacking `pay.now` are rejected since their signing
+	// time is unknown.
+	RevokeHistorical
+)
+
+// String implements fmt.Stringer.
+func (p RevokePolicy) String() string {
+	switch p {
+	case RevokeStrict:
+		return "strict"
+	case RevokeHistorical:
+		return "historical"
+	default:
+		return fmt.Sprintf("RevokePolicy(%d)", int(p))
+	}
+}
+
+// RevokedError is returned by VerifyCozAt when a coz is rejected because its
+// key is revoked.  Policy is the RevokePolicy that was applied.
+type RevokedError struct {
+	Tmb    B64
+	Rvk    Timestamp // `key.rvk`
+	Now    Timestamp // `pay.now`; zero if absent.
+	Policy RevokePolicy
+}
+
+// Error implements error.
+func (e *RevokedError) Error() string {
+	return fmt.Sprintf("Coz: key %s revoked at %d; coz now %d rejected by %s policy", e.Tmb, e.Rvk, e.Now, e.Policy)
+}
+
+// VerifyCozAt is VerifyCoz with revocation semantics.  If `key.rvk` is not set,
+// VerifyCozAt is equivalent to VerifyCoz.  If the key is revoked, policy
+// determines if cz may still be verified.  On rejection due to revocation,
+// VerifyCozAt returns false and a *RevokedError reporting the policy applied.
+func (c *Key) VerifyCozAt(cz *Coz, policy RevokePolicy) (bool, error) {
+	if !c.IsRevoked() {
+		return c.VerifyCoz(cz)
+	}
+	p := new(Pay)
+	err := p.unmarshal(cz.Pay, Limits{})
+	if err != nil {
+		return false, err
+	}
+	rErr := &RevokedError{Tmb: c.Tmb, Rvk: c.Rvk, Now: p.Now, Policy: policy}
+	switch policy {
+	default:
+		return false, fmt.Errorf("VerifyCozAt: unknown policy %s", policy)
+	case RevokeStrict:
+		return false, rErr
+	case RevokeHistorical:
+		if p.Now == 0 || p.Now >= c.Rvk {
+			return false, rErr
+		}
+	}
+	return c.VerifyCoz(cz)
+}
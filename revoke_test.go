@@ -0,0 +1,183 @@
+package coz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ExampleMemRevocationStore applies the README's self-revoke example.
+func ExampleMemRevocationStore() {
+	cz := new(Coz)
+	err := json.Unmarshal([]byte(`{
+  "pay": {
+    "alg": "ES256",
+    "msg": "Posted my private key online",
+    "now": 1623132000,
+    "rvk": 1623132000,
+    "tmb": "U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg",
+    "typ": "cyphr.me/key/revoke"
+  },
+  "sig": "EhAsIL_w51NbCtzxFUcJiRMb1KmlxFSD-g7M-9wgqH9nnVHaEHiNyecfvfkrNf--KnfZyrsDIyWuT86MLNozQg"
+}`), cz)
+	if err != nil {
+		panic(err)
+	}
+
+	gk2 := GoldenKey // Make a copy
+	s := NewMemRevocationStore()
+	fmt.Println(s.ApplyRevoke(cz, &gk2))
+	fmt.Println(gk2.Rvk)
+	fmt.Println(s.IsRevokedAt(gk2.Tmb, 1623131999))
+	fmt.Println(s.IsRevokedAt(gk2.Tmb, 1623132000))
+
+	// A non-revoke coz is rejected.
+	cz2 := new(Coz)
+	err = json.Unmarshal([]byte(GoldenCoz), cz2)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(s.ApplyRevoke(cz2, &gk2))
+
+	// Output:
+	// <nil>
+	// 1623132000
+	// false
+	// true
+	// CheckRevoke: coz is not a revoke
+}
+
+func TestFileRevocationStore(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "revokes.ndjson")
+	s, err := OpenFileRevocationStore(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := NewSigningKey(ES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cz, err := k.Revoke()
+	if err != nil {
+		t.Fatal(err)
+	}
+	k.Rvk = 0
+	err = s.ApplyRevoke(cz, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !k.IsRevoked() {
+		t.Fatal("ApplyRevoke must set key.rvk")
+	}
+
+	// Tampered signature must be rejected and not recorded.
+	k2, err := NewSigningKey(ES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cz2, err := k2.Revoke()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cz2.Sig[3] ^= 1
+	if err = s.ApplyRevoke(cz2, k2); err == nil {
+		t.Fatal("expected error on invalid signature")
+	}
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen and check persistence.
+	s, err = OpenFileRevocationStore(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	rvk, ok := s.Rvk(k.Tmb)
+	if !ok || rvk != k.Rvk {
+		t.Fatalf("expected rvk %d, given %d %t", k.Rvk, rvk, ok)
+	}
+	if _, ok = s.Rvk(k2.Tmb); ok {
+		t.Fatal("invalid revoke must not be persisted")
+	}
+
+	// A modified file must fail to open.
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[len(b)-10] ^= 1
+	err = os.WriteFile(name, b, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = OpenFileRevocationStore(name); err == nil {
+		t.Fatal("expected error on modified file")
+	}
+}
+
+func TestCheckRevoke_size(t *testing.T) {
+	original := RVK_MAX_SIZE
+	defer func() { RVK_MAX_SIZE = original }()
+
+	k, err := NewSigningKey(ES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cz, err := k.Revoke()
+	if err != nil {
+		t.Fatal(err)
+	}
+	RVK_MAX_SIZE = len(cz.Pay) - 1
+	if _, err = CheckRevoke(cz, k); err == nil {
+		t.Fatal("expected error on oversized revoke")
+	}
+}
+
+// ExampleKey_VerifyCozAt demonstrates strict and historical revocation
+// policies.
+func ExampleKey_VerifyCozAt() {
+	cz := new(Coz)
+	err := json.Unmarshal([]byte(GoldenCoz), cz) // now is 1623132000
+	if err != nil {
+		panic(err)
+	}
+
+	gk2 := GoldenKey // Make a copy
+	fmt.Println(gk2.VerifyCozAt(cz, RevokeStrict))
+
+	gk2.Rvk = 1623132001 // Revoked after the coz was signed.
+	fmt.Println(gk2.VerifyCozAt(cz, RevokeStrict))
+	fmt.Println(gk2.VerifyCozAt(cz, RevokeHistorical))
+
+	gk2.Rvk = 1623132000 // Revoked at the time the coz was signed.
+	_, err = gk2.VerifyCozAt(cz, RevokeHistorical)
+	var rErr *RevokedError
+	fmt.Println(errors.As(err, &rErr), rErr.Policy)
+
+	// Output:
+	// true <nil>
+	// false Coz: key U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg revoked at 1623132001; coz now 1623132000 rejected by strict policy
+	// true <nil>
+	// true historical
+}
+
+func TestKey_VerifyCozAt_noNow(t *testing.T) {
+	cz := new(Coz)
+	err := json.Unmarshal(GoldenEmptyCoz, cz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gk2 := GoldenKey
+	if v, err := gk2.VerifyCozAt(cz, RevokeHistorical); !v || err != nil {
+		t.Fatalf("unrevoked key must verify; %v", err)
+	}
+	gk2.Rvk = MaxSafeTimestamp
+	if v, _ := gk2.VerifyCozAt(cz, RevokeHistorical); v {
+		t.Fatal("historical policy must reject cozies without now")
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Package rotate implements key rotation chains on Coz primitives.  Key rotation
+is out of scope for the core Coz specification.
+
+A rotation is a coz signed by the old key with `typ` "coz/key/rotate" naming
+the new key in `pay.key`:
+
+	{
+	  "pay": {
+	    "alg": "ES256",
+	    "now": 1623132000,
+	    "tmb": "<old tmb>",
+	    "typ": "coz/key/rotate",
+	    "key": {"alg": "ES256", "pub": "<new pub>", "tmb": "<new tmb>"}
+	  },
+	  "sig": "<sig by old key>"
+	}
+
+ResolveChain walks rotations from a root key to the current active key.
+*/
+package rotate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cyphrme/coz"
+)
+
+// Typ is the `pay.typ` of rotation cozies.
+const Typ = "coz/key/rotate"
+
+var (
+	// ErrFork is returned when a key has rotated to more than one key.
+	ErrFork = errors.New("rotate: chain fork")
+	// ErrRevoked is returned when the chain ends in a revoked key.
+	ErrRevoked = errors.New("rotate: active key is revoked")
+)
+
+// rotation is the custom `pay` struct of a rotation coz.
+type rotation struct {
+	Key *coz.Key `json:"key"`
+}
+
+// Rotate returns a rotation coz signed by old naming next as its successor.
+// Only `alg`, `pub`, and `tmb` of next are included.
+func Rotate(old, next *coz.Key) (*coz.Coz, error) {
+	n := &coz.Key{Alg: next.Alg, Pub: next.Pub, Tmb: next.Tmb}
+	err := n.Correct()
+	if err != nil {
+		return nil, fmt.Errorf("Rotate: next key is not correct; %w", err)
+	}
+	if bytes.Equal(old.Tmb, n.Tmb) {
+		return nil, errors.New("Rotate: next key must differ from old key")
+	}
+	p := &coz.Pay{
+		Alg:    old.Alg,
+		Now:    coz.Now(),
+		Tmb:    old.Tmb,
+		Typ:    Typ,
+		Struct: &rotation{Key: n},
+	}
+	return old.SignPayRaw(p)
+}
+
+// link is a parsed rotation.
+type link struct {
+	cz  *coz.Coz
+	key *coz.Key // Next key.
+	err error    // Error decoding key, reported only if cz verifies.
+}
+
+// ResolveChain walks the rotation chain from root through cozies and returns
+// the current active key.  cozies may be in any order and may include
+// self-revokes and unrelated cozies, which are ignored.  Rotations and revokes
+// that do not verify are ignored, since anyone may add them to a shared log.
+//
+// ResolveChain errors if:
+//
+//  1. A verified rotation from a key in the chain has a missing or incorrect
+//     `key`.
+//  2. A key in the chain has verified rotations to more than one key
+//     (ErrFork).
+//  3. A key in the chain is revoked and rotated at or after `rvk`.  Rotations
+//     by a revoked key must have `now` before `rvk`.
+//  4. The chain ends in a revoked key (ErrRevoked).
+//  5. The chain is cyclic.
+func ResolveChain(cozies []*coz.Coz, root *coz.Key) (*coz.Key, error) {
+	rotations := make(map[coz.B64s][]link)
+	revokes := make(map[coz.B64s][]*coz.Coz)
+	for i, cz := range cozies {
+		p := new(coz.Pay)
+		err := json.Unmarshal(cz.Pay, p)
+		if err != nil {
+			continue // Unrelated.
+		}
+		switch {
+		case p.IsRevoke():
+			revokes[coz.B64s(p.Tmb)] = append(revokes[coz.B64s(p.Tmb)], cz)
+		case p.Typ == Typ:
+			// `key` is only decoded for rotations since unrelated cozies may use
+			// `key` for anything.
+			l := link{cz: cz}
+			r := new(rotation)
+			err = json.Unmarshal(cz.Pay, r)
+			switch {
+			case err != nil:
+				l.err = fmt.Errorf("coz %d: %w", i, err)
+			case r.Key == nil:
+				l.err = fmt.Errorf("coz %d: rotation missing key", i)
+			default:
+				l.key = r.Key
+				l.err = l.key.Correct()
+			}
+			rotations[coz.B64s(p.Tmb)] = append(rotations[coz.B64s(p.Tmb)], l)
+		}
+	}
+
+	cur := root.Public()
+	seen := map[coz.B64s]bool{}
+	for {
+		t := coz.B64s(cur.Tmb)
+		if seen[t] {
+			return nil, fmt.Errorf("ResolveChain: cycle at key %s", cur.Tmb)
+		}
+		seen[t] = true
+
+		// Honor revocations.  Only self-revokes that verify are applied.
+		for _, rcz := range revokes[t] {
+			rvk, err := coz.CheckRevoke(rcz, cur)
+			if err != nil {
+				continue
+			}
+			if !cur.IsRevoked() || rvk < cur.Rvk {
+				cur.Rvk = rvk
+			}
+		}
+
+		var next *coz.Key
+		for _, l := range rotations[t] {
+			v, err := cur.VerifyCoz(l.cz)
+			if err != nil || !v {
+				continue
+			}
+			if l.err != nil {
+				return nil, fmt.Errorf("ResolveChain: rotation from %s: %w", cur.Tmb, l.err)
+			}
+			if cur.IsRevoked() {
+				_, err = cur.VerifyCozAt(l.cz, coz.RevokeHistorical)
+				if err != nil {
+					return nil, fmt.Errorf("ResolveChain: rotation from %s: %w", cur.Tmb, err)
+				}
+			}
+			if next != nil && !bytes.Equal(next.Tmb, l.key.Tmb) {
+				return nil, fmt.Errorf("%w: key %s rotated to %s and %s", ErrFork, cur.Tmb, next.Tmb, l.key.Tmb)
+			}
+			next = l.key
+		}
+
+		if next == nil {
+			if cur.IsRevoked() {
+				return nil, fmt.Errorf("%w: key %s revoked at %d", ErrRevoked, cur.Tmb, cur.Rvk)
+			}
+			return cur, nil
+		}
+		cur = next.Public()
+	}
+}
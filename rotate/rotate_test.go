@@ -0,0 +1,157 @@
+package rotate
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cyphrme/coz"
+)
+
+func newKey(t *testing.T) *coz.Key {
+	t.Helper()
+	k, err := coz.NewKey(coz.SEAlg(coz.ES256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func rotate(t *testing.T, old, next *coz.Key) *coz.Coz {
+	t.Helper()
+	cz, err := Rotate(old, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cz
+}
+
+// revoke returns a self-revoke of k with the given `rvk`.
+func revoke(t *testing.T, k *coz.Key, rvk coz.Timestamp) *coz.Coz {
+	t.Helper()
+	cz, err := k.SignPayRaw(&coz.Pay{Alg: k.Alg, Now: rvk, Tmb: k.Tmb, Rvk: rvk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cz
+}
+
+func ExampleResolveChain() {
+	a, _ := coz.NewKey(coz.SEAlg(coz.ES256))
+	b, _ := coz.NewKey(coz.SEAlg(coz.Ed25519))
+	c, _ := coz.NewKey(coz.SEAlg(coz.ES384))
+
+	ab, err := Rotate(a, b)
+	if err != nil {
+		panic(err)
+	}
+	bc, err := Rotate(b, c)
+	if err != nil {
+		panic(err)
+	}
+
+	active, err := ResolveChain([]*coz.Coz{bc, ab}, a)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(active.Alg, active.Tmb.String() == c.Tmb.String())
+
+	// Output:
+	// ES384 true
+}
+
+func TestResolveChain(t *testing.T) {
+	a, b, c := newKey(t), newKey(t), newKey(t)
+	ab, bc := rotate(t, a, b), rotate(t, b, c)
+
+	// Root only.
+	k, err := ResolveChain(nil, a)
+	if err != nil || k.Tmb.String() != a.Tmb.String() {
+		t.Fatalf("expected root; given %v %v", k, err)
+	}
+
+	// Unrelated cozies, even with a `key` that is not a Coz key, are ignored.
+	type other struct {
+		Key string `json:"key"`
+	}
+	unrelated, err := a.SignPayRaw(&coz.Pay{Alg: a.Alg, Tmb: a.Tmb, Typ: "example/other", Struct: &other{"x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err = ResolveChain([]*coz.Coz{unrelated, ab, bc}, a)
+	if err != nil || k.Tmb.String() != c.Tmb.String() {
+		t.Fatalf("expected c with unrelated coz; given %v %v", k, err)
+	}
+
+	// Fork.
+	ac := rotate(t, a, c)
+	_, err = ResolveChain([]*coz.Coz{ab, ac}, a)
+	if !errors.Is(err, ErrFork) {
+		t.Fatalf("expected ErrFork; given %v", err)
+	}
+
+	// Rotations that do not verify are ignored, and do not fork.
+	bad := &coz.Coz{Pay: ab.Pay, Sig: append(coz.B64(nil), bc.Sig...)}
+	k, err = ResolveChain([]*coz.Coz{bad}, a)
+	if err != nil || k.Tmb.String() != a.Tmb.String() {
+		t.Fatalf("expected root with invalid signature; given %v %v", k, err)
+	}
+	forged := &coz.Coz{Pay: ac.Pay, Sig: append(coz.B64(nil), ab.Sig...)}
+	k, err = ResolveChain([]*coz.Coz{forged, ab, bc}, a)
+	if err != nil || k.Tmb.String() != c.Tmb.String() {
+		t.Fatalf("expected c with forged rotation; given %v %v", k, err)
+	}
+
+	// Pays that do not unmarshal as Pay, and unverified rotations with an
+	// invalid `key`, are ignored.
+	badKey := []byte(`{"alg":"ES256","tmb":"` + a.Tmb.String() + `","typ":"` + Typ + `","key":1}`)
+	k, err = ResolveChain([]*coz.Coz{
+		{Pay: []byte(`{"now":"x"}`), Sig: ab.Sig},
+		{Pay: badKey, Sig: ab.Sig},
+		ab, bc,
+	}, a)
+	if err != nil || k.Tmb.String() != c.Tmb.String() {
+		t.Fatalf("expected c with malformed cozies; given %v %v", k, err)
+	}
+
+	// A verified rotation with an invalid `key` errors.
+	signed, err := a.SignPayJSON(badKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ResolveChain([]*coz.Coz{signed}, a); err == nil {
+		t.Fatal("expected error on verified rotation with invalid key")
+	}
+
+	// Rotation signed by a key not in the chain is ignored.
+	k, err = ResolveChain([]*coz.Coz{bc}, a)
+	if err != nil || k.Tmb.String() != a.Tmb.String() {
+		t.Fatalf("expected root; given %v %v", k, err)
+	}
+
+	// Active key revoked.
+	_, err = ResolveChain([]*coz.Coz{ab, bc, revoke(t, c, coz.Now())}, a)
+	if !errors.Is(err, ErrRevoked) {
+		t.Fatalf("expected ErrRevoked; given %v", err)
+	}
+
+	// b revoked after rotating to c: chain continues.
+	k, err = ResolveChain([]*coz.Coz{ab, bc, revoke(t, b, coz.Now()+60)}, a)
+	if err != nil || k.Tmb.String() != c.Tmb.String() {
+		t.Fatalf("expected c; given %v %v", k, err)
+	}
+
+	// b revoked before rotating to c: rotation rejected.
+	_, err = ResolveChain([]*coz.Coz{ab, bc, revoke(t, b, 1)}, a)
+	var rErr *coz.RevokedError
+	if !errors.As(err, &rErr) {
+		t.Fatalf("expected RevokedError; given %v", err)
+	}
+
+	// Cycle.
+	ba := rotate(t, b, a)
+	_, err = ResolveChain([]*coz.Coz{ab, ba}, a)
+	if err == nil {
+		t.Fatal("expected error on cycle")
+	}
+}
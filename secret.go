@@ -0,0 +1,148 @@
+package coz
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SecretKey holds a private Coz key with `prv` stored outside of the Go heap in
+// locked memory where possible (mlock on Linux) so that it is not swapped to
+// disk.  SecretKey refuses to be printed with fmt or marshaled to JSON.  Use
+// Reveal to explicitly obtain the full Key.
+//
+// Call Destroy when finished to zero and release `prv`.  Copies of a SecretKey
+// share `prv`, so Destroy of any copy destroys all of them.
+type SecretKey struct {
+	pub Key        // Key without `prv`.
+	buf *lockedBuf // Shared by copies.
+}
+
+// lockedBuf is `prv` in locked memory.  mu is held for reading while `prv` is
+// in use and for writing by destroy, so that memory is never used after it is
+// released.
+type lockedBuf struct {
+	mu     sync.RWMutex
+	b      []byte
+	locked bool
+}
+
+var errSecretDestroyed = errors.New("SecretKey: key is destroyed")
+
+// NewSecretKey copies `prv` of k into locked memory and destroys k's `prv`.
+// k must be correct and private.
+func NewSecretKey(k *Key) (*SecretKey, error) {
+	if len(k.Prv) == 0 {
+		return nil, errors.New("NewSecretKey: key has no prv")
+	}
+	err := k.Correct()
+	if err != nil {
+		return nil, fmt.Errorf("NewSecretKey: Coz key is not correct; %w", err)
+	}
+
+	buf := new(lockedBuf)
+	buf.b, buf.locked = lockedAlloc(len(k.Prv))
+	copy(buf.b, k.Prv)
+	s := &SecretKey{pub: *k, buf: buf}
+	s.pub.Prv = nil
+	k.Destroy()
+	return s, nil
+}
+
+// Locked reports whether `prv` is held in locked memory.  Locking may fail if
+// unsupported by the platform or if RLIMIT_MEMLOCK is exceeded, in which case
+// SecretKey still zeroes `prv` on Destroy.
+func (s *SecretKey) Locked() bool {
+	if s.buf == nil {
+		return false
+	}
+	s.buf.mu.RLock()
+	defer s.buf.mu.RUnlock()
+	return s.buf.locked
+}
+
+// Public returns a copy of the key without `prv`.
+func (s *SecretKey) Public() Key {
+	return s.pub
+}
+
+// use calls f with the full key, `prv` referencing locked memory, and errors if
+// s is destroyed.  `prv` must not be retained by f.
+func (s *SecretKey) use(f func(k *Key) error) error {
+	if s.buf == nil {
+		return errSecretDestroyed
+	}
+	s.buf.mu.RLock()
+	defer s.buf.mu.RUnlock()
+	if s.buf.b == nil {
+		return errSecretDestroyed
+	}
+	k := s.pub
+	k.Prv = s.buf.b
+	return f(&k)
+}
+
+// Reveal returns a full copy of the key, including `prv`, in ordinary memory.
+// The caller should call Destroy on the returned key when finished.
+func (s *SecretKey) Reveal() (k *Key, err error) {
+	err = s.use(func(l *Key) error {
+		k = l
+		k.Prv = append(B64(nil), l.Prv...)
+		return nil
+	})
+	return k, err
+}
+
+// Sign signs digest without copying `prv` out of locked memory.  See Key.Sign.
+func (s *SecretKey) Sign(digest B64) (sig B64, err error) {
+	err = s.use(func(k *Key) error {
+		sig, err = k.Sign(digest)
+		return err
+	})
+	return sig, err
+}
+
+// SignPay signs pay without copying `prv` out of locked memory.  See
+// Key.SignPay.
+func (s *SecretKey) SignPay(p *Pay) (cz *Coz, err error) {
+	err = s.use(func(k *Key) error {
+		cz, err = k.SignPay(p)
+		return err
+	})
+	return cz, err
+}
+
+// Destroy zeroes `prv` and releases the locked memory.  SecretKey, and every
+// copy of it, is unusable afterwards.
+func (s *SecretKey) Destroy() {
+	if s.buf == nil {
+		return
+	}
+	s.buf.mu.Lock()
+	defer s.buf.mu.Unlock()
+	if s.buf.b == nil {
+		return
+	}
+	clear(s.buf.b)
+	lockedFree(s.buf.b, s.buf.locked)
+	s.buf.b = nil
+	s.buf.locked = false
+}
+
+// String implements fmt.Stringer and prints only the public key.
+func (s SecretKey) String() string {
+	return s.pub.String()
+}
+
+// Format implements fmt.Formatter so that all verbs, including `%#v`, print
+// only the public key.  String, Format, and MarshalJSON have value receivers
+// so that SecretKey values, including those embedded in other structs, are
+// covered as well as pointers.
+func (s SecretKey) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, "SecretKey%s", s.pub.String())
+}
+
+// MarshalJSON always errors.  Use Reveal and marshal the returned key.
+func (s SecretKey) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("SecretKey: refusing to marshal; use Reveal")
+}
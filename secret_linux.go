@@ -0,0 +1,30 @@
+//go:build linux
+
+package coz
+
+import "syscall"
+
+// lockedAlloc returns size bytes of anonymous memory, outside of the Go heap,
+// locked with mlock.  If mapping or locking fails, ordinary heap memory is
+// returned and locked is false.
+func lockedAlloc(size int) (b []byte, locked bool) {
+	b, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return make([]byte, size), false
+	}
+	if syscall.Mlock(b) != nil {
+		_ = syscall.Munmap(b)
+		return make([]byte, size), false
+	}
+	return b, true
+}
+
+// lockedFree unlocks and unmaps memory from lockedAlloc.  b must already be
+// zeroed.
+func lockedFree(b []byte, locked bool) {
+	if !locked {
+		return
+	}
+	_ = syscall.Munlock(b)
+	_ = syscall.Munmap(b)
+}
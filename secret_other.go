@@ -0,0 +1,12 @@
+//go:build !linux
+
+package coz
+
+// lockedAlloc returns ordinary heap memory.  Memory locking is only
+// implemented on Linux.
+func lockedAlloc(size int) (b []byte, locked bool) {
+	return make([]byte, size), false
+}
+
+// lockedFree is a no-op on platforms without memory locking.
+func lockedFree(b []byte, locked bool) {}
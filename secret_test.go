@@ -0,0 +1,162 @@
+package coz
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// ExampleSecretKey demonstrates that SecretKey does not print or marshal
+// `prv`.
+func ExampleSecretKey() {
+	gk2 := GoldenKey
+	gk2.Prv = append(B64(nil), GoldenKey.Prv...)
+	s, err := NewSecretKey(&gk2)
+	if err != nil {
+		panic(err)
+	}
+	defer s.Destroy()
+
+	fmt.Println(gk2.Prv == nil) // Source key is destroyed.
+	fmt.Printf("%v\n", s)
+	fmt.Printf("%#v\n", s)
+	_, err = json.Marshal(s)
+	fmt.Println(err)
+
+	k, err := s.Reveal()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(k.Prv)
+
+	// Output:
+	// true
+	// SecretKey{"alg":"ES256","now":1623132000,"tag":"Zami's Majuscule Key.","tmb":"U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg","pub":"2nTOaFVm2QLxmUO_SjgyscVHBtvHEfo2rq65MvgNRjORojq39Haq9rXNxvXxwba_Xj0F5vZibJR3isBdOWbo5g"}
+	// SecretKey{"alg":"ES256","now":1623132000,"tag":"Zami's Majuscule Key.","tmb":"U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg","pub":"2nTOaFVm2QLxmUO_SjgyscVHBtvHEfo2rq65MvgNRjORojq39Haq9rXNxvXxwba_Xj0F5vZibJR3isBdOWbo5g"}
+	// json: error calling MarshalJSON for type *coz.SecretKey: SecretKey: refusing to marshal; use Reveal
+	// bNstg4_H3m3SlROufwRSEgibLrBuRq9114OvdapcpVA
+}
+
+func TestSecretKey(t *testing.T) {
+	for _, alg := range []SigAlg{ES256, Ed25519} {
+		k, err := NewSigningKey(alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := NewSecretKey(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := s.Public()
+		cz, err := s.SignPay(&Pay{Alg: pub.Alg, Tmb: pub.Tmb})
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := pub.VerifyCoz(cz)
+		if !v || err != nil {
+			t.Fatalf("%s: signature by SecretKey failed to verify; %v", alg, err)
+		}
+
+		s.Destroy()
+		if _, err = s.Sign(cz.Cad); err == nil {
+			t.Fatalf("%s: destroyed SecretKey must not sign", alg)
+		}
+	}
+}
+
+// TestSecretKey_value ensures `prv` is not printed or marshalled from a
+// SecretKey value, including when embedded by value.
+func TestSecretKey_value(t *testing.T) {
+	k, err := NewSigningKey(ES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prv := k.Prv.String()
+	s, err := NewSecretKey(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Destroy()
+	embed := struct {
+		Name string
+		S    SecretKey
+	}{"a", *s}
+
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%x", "%q"} {
+		for _, v := range []any{*s, embed, &embed} {
+			out := fmt.Sprintf(verb, v)
+			if strings.Contains(out, prv) || strings.Contains(out, fmt.Sprint([]byte(MustDecode(prv)))) ||
+				strings.Contains(out, fmt.Sprintf("%x", []byte(MustDecode(prv)))) {
+				t.Fatalf("%s of %T printed prv: %s", verb, v, out)
+			}
+		}
+	}
+	if _, err = json.Marshal(*s); err == nil {
+		t.Fatal("expected marshal error for SecretKey value")
+	}
+	if _, err = json.Marshal(embed); err == nil {
+		t.Fatal("expected marshal error for embedded SecretKey value")
+	}
+}
+
+func TestMarshalPretty_redact(t *testing.T) {
+	cz := new(Coz)
+	err := json.Unmarshal([]byte(GoldenCozWKey), cz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []any{GoldenKey, &GoldenKey, cz, *cz} {
+		b, err := MarshalPretty(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(b), `"prv"`) {
+			t.Fatalf("MarshalPretty must redact prv; given %s", b)
+		}
+	}
+	if strings.Contains(cz.String(), `"prv"`) {
+		t.Fatal("Coz.String must redact prv")
+	}
+	if len(cz.Key.Prv) == 0 || len(GoldenKey.Prv) == 0 {
+		t.Fatal("redaction must not modify the original")
+	}
+}
+
+// TestSecretKey_copy ensures that Destroy is seen by copies of a SecretKey,
+// which must then error instead of using released memory.
+func TestSecretKey_copy(t *testing.T) {
+	k, err := NewSigningKey(ES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSecretKey(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2 := *s
+	digest := make(B64, 32)
+	if _, err = s2.Sign(digest); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Destroy()
+	if _, err = s2.Sign(digest); err == nil {
+		t.Fatal("copy of destroyed SecretKey must not sign")
+	}
+	if _, err = s2.SignPay(&Pay{}); err == nil {
+		t.Fatal("copy of destroyed SecretKey must not sign pay")
+	}
+	if _, err = s2.Reveal(); err == nil {
+		t.Fatal("copy of destroyed SecretKey must not reveal")
+	}
+	if s2.Locked() {
+		t.Fatal("copy of destroyed SecretKey must not be locked")
+	}
+	s2.Destroy() // No-op.
+
+	var zero SecretKey
+	if _, err = zero.Sign(digest); err == nil {
+		t.Fatal("zero SecretKey must not sign")
+	}
+}
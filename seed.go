@@ -0,0 +1,148 @@
+package coz
+
+import (
+	"crypto/ed25519"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// SeedSalt is the HKDF salt used by NewKeyFromSeed for domain separation from
+// other uses of the same seed.
+const SeedSalt = "coz/seed"
+
+// MinSeedSize is the minimum seed size in bytes (128 bits).
+const MinSeedSize = 16
+
+// NewKeyFromSeed deterministically derives a Coz key from seed.  The same alg
+// and seed always produce the same `prv`, `pub`, and `tmb`.  `now` is not set
+// so that the key is fully reproducible; set it if needed.
+//
+// The private component is derived with HKDF-SHA-512 using salt SeedSalt and
+// info "<alg>:<counter>".  For ECDSA, candidates outside [1, N-1] are rejected
+// and the counter incremented (rejection sampling).  For Ed25519, the derived
+// 32 bytes are the Ed25519 seed.
+func NewKeyFromSeed(alg SEAlg, seed []byte) (c *Key, err error) {
+	if len(seed) < MinSeedSize {
+		return nil, fmt.Errorf("NewKeyFromSeed: seed must be at least %d bytes; given %d", MinSeedSize, len(seed))
+	}
+	c = new(Key)
+	c.Alg = alg
+
+	switch c.Alg.SigAlg() {
+	default:
+		return nil, fmt.Errorf("NewKeyFromSeed: unsupported alg %q", alg)
+	case ES224, ES256, ES384, ES512:
+		n := curveOrders[c.Alg.SigAlg()]
+		excess := uint(alg.PrvSize()*8 - n.BitLen()) // ES512 is 528 bits for a 521 bit order.
+		d := new(big.Int)
+		for i := 0; ; i++ {
+			if i > 255 { // Probability is negligible for all supported curves.
+				return nil, errors.New("NewKeyFromSeed: rejection sampling exhausted")
+			}
+			b, err := seedKDF(alg, seed, i, alg.PrvSize())
+			if err != nil {
+				return nil, err
+			}
+			b[0] &= 0xFF >> excess
+			d.SetBytes(b)
+			if d.Sign() != 0 && d.Cmp(n) < 0 {
+				c.Prv = b
+				break
+			}
+		}
+	case Ed25519, Ed25519ph:
+		c.Prv, err = seedKDF(alg, seed, 0, ed25519.SeedSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.Pub = c.calcPub()
+	return c, c.Thumbprint()
+}
+
+// seedKDF derives size bytes from seed for alg and counter i.
+func seedKDF(alg SEAlg, seed []byte, i, size int) ([]byte, error) {
+	return hkdf.Key(sha512.New, seed, []byte(SeedSalt), fmt.Sprintf("%s:%d", alg, i), size)
+}
+
+// bip39English is the BIP-39 English word list.
+// https://github.com/bitcoin/bips/blob/master/bip-0039/english.txt
+//
+//go:embed bip39_english.txt
+var bip39English string
+
+var (
+	bip39Words = strings.Fields(bip39English)
+	bip39Index = func() map[string]int {
+		m := make(map[string]int, len(bip39Words))
+		for i, w := range bip39Words {
+			m[w] = i
+		}
+		return m
+	}()
+)
+
+// EncodeMnemonic encodes seed as a BIP-39 English mnemonic.  Seed must be 16,
+// 20, 24, 28, or 32 bytes, producing 12, 15, 18, 21, or 24 words respectively.
+//
+// Only the BIP-39 entropy encoding is used.  The BIP-39 PBKDF2 "seed"
+// derivation is not applied; DecodeMnemonic returns the original seed for use
+// with NewKeyFromSeed.
+func EncodeMnemonic(seed B64) (string, error) {
+	if len(seed) < 16 || len(seed) > 32 || len(seed)%4 != 0 {
+		return "", fmt.Errorf("EncodeMnemonic: seed must be 16, 20, 24, 28, or 32 bytes; given %d", len(seed))
+	}
+	cs := sha256.Sum256(seed)
+	csBits := len(seed) / 4
+
+	// Entropy bits followed by checksum bits.
+	bits := new(big.Int).SetBytes(seed)
+	bits.Lsh(bits, uint(csBits))
+	bits.Or(bits, big.NewInt(int64(cs[0]>>(8-csBits))))
+
+	n := (len(seed)*8 + csBits) / 11
+	words := make([]string, n)
+	mask := big.NewInt(2047)
+	for i := n - 1; i >= 0; i-- {
+		words[i] = bip39Words[new(big.Int).And(bits, mask).Int64()]
+		bits.Rsh(bits, 11)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// DecodeMnemonic decodes a BIP-39 English mnemonic into seed and verifies the
+// checksum.  See EncodeMnemonic.
+func DecodeMnemonic(mnemonic string) (seed B64, err error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, fmt.Errorf("DecodeMnemonic: mnemonic must be 12, 15, 18, 21, or 24 words; given %d", len(words))
+	}
+
+	bits := new(big.Int)
+	for _, w := range words {
+		i, ok := bip39Index[strings.ToLower(w)]
+		if !ok {
+			return nil, fmt.Errorf("DecodeMnemonic: unknown word %q", w)
+		}
+		bits.Lsh(bits, 11)
+		bits.Or(bits, big.NewInt(int64(i)))
+	}
+
+	csBits := len(words) / 3
+	cs := new(big.Int).And(bits, big.NewInt(int64(1<<csBits-1)))
+	bits.Rsh(bits, uint(csBits))
+	seed = bits.FillBytes(make([]byte, (len(words)*11-csBits)/8))
+
+	sum := sha256.Sum256(seed)
+	if cs.Int64() != int64(sum[0]>>(8-csBits)) {
+		return nil, errors.New("DecodeMnemonic: invalid checksum")
+	}
+	return seed, nil
+}
@@ -0,0 +1,131 @@
+package coz
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// ExampleNewKeyFromSeed demonstrates that a seed always derives the same key.
+func ExampleNewKeyFromSeed() {
+	seed := MustDecode("AAECAwQFBgcICQoLDA0ODw")
+	for _, alg := range []SigAlg{ES256, Ed25519} {
+		k, err := NewKeyFromSeed(SEAlg(alg), seed)
+		if err != nil {
+			panic(err)
+		}
+		k2, err := NewKeyFromSeed(SEAlg(alg), seed)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(k.Alg, bytes.Equal(k.Prv, k2.Prv), k.Correct())
+	}
+
+	// Output:
+	// ES256 true <nil>
+	// Ed25519 true <nil>
+}
+
+// ExampleEncodeMnemonic uses the BIP-39 test vectors.
+func ExampleEncodeMnemonic() {
+	m, err := EncodeMnemonic(bytes.Repeat([]byte{0x7f}, 16))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(m)
+
+	m, err = EncodeMnemonic(bytes.Repeat([]byte{0xff}, 32))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(m)
+
+	seed, err := DecodeMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%x\n", []byte(seed))
+
+	_, err = DecodeMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon")
+	fmt.Println(err)
+
+	// Output:
+	// legal winner thank year wave sausage worth useful legal winner thank yellow
+	// zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote
+	// 00000000000000000000000000000000
+	// DecodeMnemonic: invalid checksum
+}
+
+// TestNewKeyFromSeed_golden pins derivation so that a change, which would
+// break recovery of existing keys from their seed, is detected.  Seed is
+// 0x000102...0f.
+func TestNewKeyFromSeed_golden(t *testing.T) {
+	seed := MustDecode("AAECAwQFBgcICQoLDA0ODw")
+	for _, v := range []struct {
+		alg SigAlg
+		prv string
+		tmb string
+	}{
+		{ES224, "iixIEHTnSkb6ENis4XiCR-kzuvzGlVvakyIx1g", "zfnKnnUcy5E3tAz4SqZ4kR8nmR41z6MX2SqxZg"},
+		{ES256, "J_HrXts6DVbQwn5ag0fjwEFc4uTIOiVNGDzDq80v5iw", "YfJ19H0tKG6ksLCxWeA3Brk8vEkv2eaFhW54It_cUJs"},
+		{ES384, "fTZTJRLsfqiOAgv330n2u8f6XLYFtCAr4DZjMizHoE_mTZfJN1hSqkxCX7GOwlKp", "AaEW9c7bbahaj9jawjj8GzQ8F1F-lOVJlry_iDO3TXXUnaRG1qEFpEqEhGmZ2BZB"},
+		{ES512, "AbDy0uMtv-Tbj1HVE547INEMpERwLYTiWA6lPJcRXNILsOf0o2e0TAQrmviAUz0-YHRpfg7yOQsMRX1JWlj2Kk4T", "bUU5Ru7sV6N1W7rDRvxZOGdSS2qvxBlHQilw9qfAFzzcEU33O4rolQvQzxzkthlE1JmAutXRIe5YOWosoBOr2g"},
+		{Ed25519, "WgvOaYzdH6m_rZhMI2qa8h1sw4En5MsVTQ-J7BHmobI", "yJCNGugDLPSwlfc8giBwtBqPgTDXUY1uKBPox4_Wf9oiN3YDZBQcNuCC8KP0152o_PYKLI7VRpoHYcExOdoZPw"},
+	} {
+		k, err := NewKeyFromSeed(SEAlg(v.alg), seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.Prv.String() != v.prv || k.Tmb.String() != v.tmb {
+			t.Errorf("%s: expected prv %s tmb %s, given prv %s tmb %s", v.alg, v.prv, v.tmb, k.Prv, k.Tmb)
+		}
+	}
+}
+
+func TestNewKeyFromSeed(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, 32)
+	for _, alg := range []SigAlg{ES224, ES256, ES384, ES512, Ed25519} {
+		k, err := NewKeyFromSeed(SEAlg(alg), seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !k.Valid() {
+			t.Fatalf("%s: derived key is invalid", alg)
+		}
+		// Recalculating tmb from pub must be stable.
+		tmb, err := Thumbprint(&Key{Alg: k.Alg, Pub: k.calcPub()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(tmb, k.Tmb) {
+			t.Fatalf("%s: tmb not reproducible", alg)
+		}
+
+		// Different seeds produce different keys.
+		k2, err := NewKeyFromSeed(SEAlg(alg), bytes.Repeat([]byte{0x43}, 32))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(k.Prv, k2.Prv) {
+			t.Fatalf("%s: different seeds produced the same key", alg)
+		}
+	}
+
+	// Seed to mnemonic and back derives the same key.
+	m, err := EncodeMnemonic(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed2, err := DecodeMnemonic(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(seed, seed2) {
+		t.Fatalf("mnemonic round trip; expected %x, given %x", seed, seed2)
+	}
+
+	_, err = NewKeyFromSeed(SEAlg(ES256), []byte("short"))
+	if err == nil {
+		t.Fatal("expected error on short seed")
+	}
+}
@@ -0,0 +1,156 @@
+package coz
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxRecordSize is the default maximum size in bytes of a single
+// newline delimited coz for Decoder.
+const DefaultMaxRecordSize = 1 << 20
+
+// DecodeError is a Decoder error with the 1-indexed line number of the
+// offending record.
+type DecodeError struct {
+	Line int
+	Err  error
+}
+
+// Error implements error.
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("Decoder: line %d: %s", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Decoder reads a stream of newline delimited cozies (NDJSON).  Each line is
+// one JSON coz and is unmarshaled with Coz.UnmarshalJSON, which rejects
+// duplicate fields.  Blank lines are skipped.
+type Decoder struct {
+	r       *bufio.Reader
+	line    int
+	maxSize int
+	meta    bool
+	keys    KeySource
+}
+
+// NewDecoder returns a new Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r), maxSize: DefaultMaxRecordSize}
+}
+
+// SetMaxSize sets the maximum size in bytes of a single record, excluding the
+// newline.  If n is less than 1, there is no limit.
+func (d *Decoder) SetMaxSize(n int) {
+	d.maxSize = n
+}
+
+// SetMeta sets whether Decode calls Meta on each coz.
+func (d *Decoder) SetMeta(meta bool) {
+	d.meta = meta
+}
+
+// SetVerify sets the source of keys for verifying each coz by `pay.tmb`.  If
+// src is nil, cozies are not verified.  Cozies that fail verification are
+// returned with an error.  See VerifyWith.
+func (d *Decoder) SetVerify(src KeySource) {
+	d.keys = src
+}
+
+// Line returns the line number of the last record read.
+func (d *Decoder) Line() int {
+	return d.line
+}
+
+// Decode reads and returns the next coz.  At the end of the stream Decode
+// returns io.EOF.  Errors for individual records are *DecodeError, after
+// which Decode may be called again to continue with the next line.
+func (d *Decoder) Decode() (*Coz, error) {
+	for {
+		b, err := d.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(b)) == 0 {
+			continue
+		}
+
+		cz := new(Coz)
+		err = json.Unmarshal(b, cz)
+		if err != nil {
+			return nil, &DecodeError{Line: d.line, Err: err}
+		}
+		if d.meta {
+			if err = cz.Meta(); err != nil {
+				return nil, &DecodeError{Line: d.line, Err: err}
+			}
+		}
+		if d.keys != nil {
+			v, err := VerifyWith(d.keys, cz)
+			if err == nil && !v {
+				err = errors.New("invalid signature")
+			}
+			if err != nil {
+				return cz, &DecodeError{Line: d.line, Err: err}
+			}
+		}
+		return cz, nil
+	}
+}
+
+// readLine returns the next line without the trailing newline.  Oversized
+// lines are consumed and return an error.
+func (d *Decoder) readLine() ([]byte, error) {
+	var line []byte
+	tooLong := false
+	for {
+		frag, err := d.r.ReadSlice('\n')
+		if !tooLong {
+			line = append(line, frag...)
+			if d.maxSize > 0 && len(bytes.TrimRight(line, "\r\n")) > d.maxSize {
+				tooLong, line = true, nil
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF && len(line) == 0 && !tooLong {
+			return nil, io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		d.line++
+		if tooLong {
+			return nil, &DecodeError{Line: d.line, Err: fmt.Errorf("record exceeds max size %d", d.maxSize)}
+		}
+		return bytes.TrimRight(line, "\r\n"), nil
+	}
+}
+
+// Encoder writes newline delimited cozies (NDJSON).
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns a new Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes cz as compact JSON followed by a newline.
+func (e *Encoder) Encode(cz *Coz) error {
+	b, err := Marshal(cz)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(append(b, '\n'))
+	return err
+}
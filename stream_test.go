@@ -0,0 +1,93 @@
+package coz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func ExampleDecoder() {
+	cz := new(Coz)
+	err := json.Unmarshal([]byte(GoldenCoz), cz)
+	if err != nil {
+		panic(err)
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	for range 2 {
+		if err = enc.Encode(cz); err != nil {
+			panic(err)
+		}
+	}
+	buf.WriteString(`{"pay":{"alg":"ES256","alg":"ES256"},"sig":"AA"}` + "\n")
+
+	kr := NewKeyring()
+	if err = kr.Add(&GoldenKey); err != nil {
+		panic(err)
+	}
+	dec := NewDecoder(&buf)
+	dec.SetMeta(true)
+	dec.SetVerify(kr)
+	for {
+		cz, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(dec.Line(), cz.Czd)
+	}
+
+	// Output:
+	// 1 xrYMu87EXes58PnEACcDW1t0jF2ez4FCN-njTF0MHNo
+	// 2 xrYMu87EXes58PnEACcDW1t0jF2ez4FCN-njTF0MHNo
+	// Decoder: line 3: Coz: JSON duplicate field "alg"
+}
+
+func TestDecoder(t *testing.T) {
+	pay, err := compact([]byte(GoldenPay))
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := `{"pay":` + string(pay) + `,"sig":"` + GoldenSig + `"}`
+	bad := `{"pay":{},"sig":"` + GoldenSig + `"}`
+	long := `{"pay":{"msg":"` + strings.Repeat("a", 5000) + `"},"sig":"AA"}`
+	in := line + "\r\n\n" + long + "\n" + bad + "\n" + line // No trailing newline.
+
+	kr := NewKeyring()
+	if err = kr.Add(&GoldenKey); err != nil {
+		t.Fatal(err)
+	}
+	dec := NewDecoder(strings.NewReader(in))
+	dec.SetMaxSize(len(line))
+	dec.SetVerify(kr)
+
+	type result struct {
+		line int
+		ok   bool
+	}
+	var got []result
+	for {
+		_, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		var dErr *DecodeError
+		if err != nil && !errors.As(err, &dErr) {
+			t.Fatal(err)
+		}
+		got = append(got, result{dec.Line(), err == nil})
+	}
+	// Line 2 is blank, line 3 is too long, line 4 has no tmb to verify with.
+	want := []result{{1, true}, {3, false}, {4, false}, {5, true}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, given %v", want, got)
+	}
+}
@@ -0,0 +1,187 @@
+package coz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ParseOptions configures ParseCoz.  The zero value applies no checks beyond
+// those of Coz.UnmarshalJSON.
+//
+// encoding/json replaces invalid UTF-8 and lone surrogates with U+FFFD rather
+// than erroring, so two distinct inputs may unmarshal to the same value.  The
+// Coz docs require that non-strict UTF-8 must error.  When built with the
+// encoding/json/v2 backend (tag coz_jsonv2), invalid UTF-8 always errors, even
+// with the zero value.  See json_v2.go.
+type ParseOptions struct {
+	// UTF8 rejects invalid UTF-8, a leading byte order mark (BOM), and lone
+	// surrogates in `\u` escapes.
+	UTF8 bool
+
+	// Numbers rejects numbers not in their shortest form, as serialized by
+	// ECMAScript and RFC 8785, e.g. "1.0", "1e3", "-0", and "1E+2" are rejected
+	// for "1", "1000", "0", and "100".  Integers beyond 2^53 that are not
+	// exactly representable are rejected as well.
+	Numbers bool
+
+	// Limits, if set, overrides DefaultLimits.  See Coz.Limits.
+	Limits *Limits
+}
+
+// StrictParseOptions returns ParseOptions with every check enabled.
+func StrictParseOptions() ParseOptions {
+	return ParseOptions{UTF8: true, Numbers: true}
+}
+
+// SyntaxError is returned by ParseCoz and ValidateJSON for input rejected by
+// ParseOptions.  Offset is the byte offset of the offending input.
+type SyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+// Error implements error.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("Coz: %s at offset %d", e.Msg, e.Offset)
+}
+
+// ParseCoz validates b according to opts and unmarshals it as a coz.  See
+// ValidateJSON and Coz.UnmarshalJSON.
+func ParseCoz(b []byte, opts ParseOptions) (*Coz, error) {
+	err := ValidateJSON(b, opts)
+	if err != nil {
+		return nil, err
+	}
+	cz := &Coz{Limits: opts.Limits}
+	err = json.Unmarshal(b, cz)
+	if err != nil {
+		return nil, err
+	}
+	return cz, nil
+}
+
+// ValidateJSON checks that b is valid JSON and errors with *SyntaxError on
+// input rejected by opts.
+func ValidateJSON(b []byte, opts ParseOptions) error {
+	if opts.UTF8 {
+		if bytes.HasPrefix(b, []byte("\xEF\xBB\xBF")) {
+			return &SyntaxError{Offset: 0, Msg: "byte order mark"}
+		}
+		if !utf8.Valid(b) {
+			i := 0
+			for i < len(b) {
+				r, size := utf8.DecodeRune(b[i:])
+				if r == utf8.RuneError && size <= 1 {
+					break
+				}
+				i += size
+			}
+			return &SyntaxError{Offset: i, Msg: "invalid UTF-8"}
+		}
+	}
+	if !json.Valid(b) {
+		return errors.New("Coz: invalid JSON")
+	}
+	if !opts.UTF8 && !opts.Numbers {
+		return nil
+	}
+
+	// b is valid JSON, so only strings and numbers need scanning.
+	for i := 0; i < len(b); {
+		switch c := b[i]; {
+		case c == '"':
+			n, err := scanString(b, i, opts.UTF8)
+			if err != nil {
+				return err
+			}
+			i = n
+		case c == '-' || ('0' <= c && c <= '9'):
+			j := i + 1
+			for j < len(b) && bytes.IndexByte([]byte("0123456789.eE+-"), b[j]) >= 0 {
+				j++
+			}
+			if opts.Numbers && !isShortestNumber(string(b[i:j])) {
+				return &SyntaxError{Offset: i, Msg: fmt.Sprintf("non-shortest number %s", b[i:j])}
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+	return nil
+}
+
+// scanString scans the string starting at the quote b[i] and returns the
+// index after the closing quote.  If surrogates is set, `\u` escapes of lone
+// surrogates error.
+func scanString(b []byte, i int, surrogates bool) (int, error) {
+	for i++; i < len(b); i++ {
+		switch b[i] {
+		case '"':
+			return i + 1, nil
+		case '\\':
+			i++
+			if b[i] != 'u' {
+				continue
+			}
+			r := hex4(b[i+1 : i+5])
+			i += 4
+			if !surrogates || r < 0xD800 || r > 0xDFFF {
+				continue
+			}
+			if r >= 0xDC00 { // Low surrogate without a preceding high.
+				return 0, &SyntaxError{Offset: i - 5, Msg: "lone surrogate"}
+			}
+			if i+6 >= len(b) || b[i+1] != '\\' || b[i+2] != 'u' {
+				return 0, &SyntaxError{Offset: i - 5, Msg: "lone surrogate"}
+			}
+			lo := hex4(b[i+3 : i+7])
+			if lo < 0xDC00 || lo > 0xDFFF {
+				return 0, &SyntaxError{Offset: i - 5, Msg: "lone surrogate"}
+			}
+			i += 6
+		}
+	}
+	return i, nil
+}
+
+// hex4 decodes 4 hex digits.  Input must be valid, as guaranteed by json.Valid.
+func hex4(b []byte) rune {
+	r, _ := strconv.ParseUint(string(b), 16, 16)
+	return rune(r)
+}
+
+// isShortestNumber reports whether the JSON number lit is in its ECMAScript
+// serialized form.  See RFC 8785 section 3.2.2.3.
+func isShortestNumber(lit string) bool {
+	f, err := strconv.ParseFloat(lit, 64)
+	if err != nil || math.IsInf(f, 0) {
+		return false
+	}
+	return lit == formatNumber(f)
+}
+
+// formatNumber serializes f as ECMAScript's Number.prototype.toString.
+func formatNumber(f float64) string {
+	if f == 0 {
+		return "0" // Includes -0.
+	}
+	format := byte('f')
+	if a := math.Abs(f); a < 1e-6 || a >= 1e21 {
+		format = 'e'
+	}
+	s := strconv.FormatFloat(f, format, -1, 64)
+	if format == 'e' {
+		// Go pads the exponent to two digits, e.g. "1e-07" for "1e-7".
+		n := len(s)
+		if n >= 4 && s[n-4] == 'e' && s[n-2] == '0' {
+			s = s[:n-2] + s[n-1:]
+		}
+	}
+	return s
+}
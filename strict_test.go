@@ -0,0 +1,123 @@
+package coz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+	"unicode/utf8"
+)
+
+func ExampleParseCoz() {
+	_, err := ParseCoz([]byte(GoldenCoz), StrictParseOptions())
+	fmt.Println(err)
+
+	// `1.0` and `1` unmarshal to the same value but have different `cad`s.
+	bad := []byte(`{"pay":{"n":1.0},"sig":"AA"}`)
+	_, err = ParseCoz(bad, ParseOptions{})
+	fmt.Println(err)
+	_, err = ParseCoz(bad, StrictParseOptions())
+	fmt.Println(err)
+
+	// Output:
+	// <nil>
+	// <nil>
+	// Coz: non-shortest number 1.0 at offset 12
+}
+
+func TestValidateJSON(t *testing.T) {
+	strict := StrictParseOptions()
+	for _, s := range []string{
+		`{"a":"\ud83d\ude00😀"}`, // Surrogate pair.
+		`{"a":"é\\u"}`,          // Escaped backslash.
+		`{"a":[0,1,-1,1.5,1e+21,1e-7,0.000001,123456789]}`,
+		`{"a":"1.0","b":true}`,     // Number forms inside strings are not numbers.
+		`{"a":"` + "\uFEFF" + `"}`, // BOM is only rejected at the start.
+	} {
+		if err := ValidateJSON([]byte(s), strict); err != nil {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+
+	for _, s := range []string{
+		"\xEF\xBB\xBF{}",
+		`{"a":"` + "\xc3" + `"}`,
+		`{"a":"\ud83d"}`,
+		`{"a":"\ude00"}`,
+		`{"a":"\ud83dA"}`,
+		`{"a":"\ud83dx"}`,
+		`{"a":1.0}`,
+		`{"a":1e3}`,
+		`{"a":1E+21}`,
+		`{"a":1e021}`,
+		`{"a":-0}`,
+		`{"a":0.10}`,
+		`{"a":9007199254740993}`,
+		`{"a":1e400}`,
+	} {
+		var se *SyntaxError
+		if err := ValidateJSON([]byte(s), strict); !errors.As(err, &se) {
+			t.Errorf("%q: expected *SyntaxError, given %v", s, err)
+		}
+		// Lax accepts anything encoding/json accepts.
+		if err := ValidateJSON([]byte(s), ParseOptions{}); (err == nil) != json.Valid([]byte(s)) {
+			t.Errorf("%q: lax disagrees with json.Valid: %v", s, err)
+		}
+	}
+}
+
+// FuzzValidateJSON ensures strict validation never panics, that accepted input
+// is valid UTF-8 and JSON, and that accepted input unmarshals without
+// replacement characters being introduced.
+func FuzzValidateJSON(f *testing.F) {
+	f.Add([]byte(GoldenCoz))
+	f.Add([]byte(`{"a":"\ud83d\ude00","b":[1.5,-2,1e+21]}`))
+	f.Add([]byte(`{"a":"\ud83d"}`))
+	f.Add([]byte("\xEF\xBB\xBF{}"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		err := ValidateJSON(b, StrictParseOptions())
+		if err != nil {
+			return
+		}
+		if !utf8.Valid(b) || !json.Valid(b) {
+			t.Fatalf("accepted invalid input %q", b)
+		}
+		var v any
+		if err = json.Unmarshal(b, &v); err != nil {
+			t.Fatal(err)
+		}
+		r, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !utf8.Valid(r) {
+			t.Fatalf("invalid UTF-8 after round trip of %q", b)
+		}
+		_, _ = ParseCoz(b, StrictParseOptions())
+	})
+}
+
+// FuzzFormatNumber ensures that formatNumber output is always accepted as
+// shortest and parses back to the same value.
+func FuzzFormatNumber(f *testing.F) {
+	f.Add(0.0)
+	f.Add(1623132000.0)
+	f.Add(1e21)
+	f.Add(1e-7)
+	f.Add(-123.456)
+	f.Fuzz(func(t *testing.T, x float64) {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return
+		}
+		s := formatNumber(x)
+		if !isShortestNumber(s) {
+			t.Fatalf("%v formatted as %s is not shortest", x, s)
+		}
+		y, err := strconv.ParseFloat(s, 64)
+		if err != nil || (y != x && !(x == 0 && y == 0)) {
+			t.Fatalf("%v formatted as %s parsed as %v, %v", x, s, y, err)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package coz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	// ErrNoTilde is returned when `pay` has no `~` field.
+	ErrNoTilde = errors.New("Coz: pay has no \"~\" field")
+	// ErrTildeNotLast is returned by CheckTilde when `~` is not the last field.
+	ErrTildeNotLast = errors.New("Coz: \"~\" is not the last pay field")
+)
+
+// TildeMode selects how strictly the position of `~` is checked.
+type TildeMode int
+
+const (
+	// TildeLax accepts `~` in any position.
+	TildeLax TildeMode = iota
+	// TildeStrict requires `~`, if present, to be the last field.
+	TildeStrict
+)
+
+// SetTilde sets the tilde encapsulated sub-object `~` to v, which must marshal
+// to a JSON object.  Pay.MarshalJSON always emits `~` last, after the standard
+// fields and `Struct`.  Setting v to nil removes `~`.
+func (p *Pay) SetTilde(v any) error {
+	if v == nil {
+		p.tilde = nil
+		return nil
+	}
+	b, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 || b[0] != '{' {
+		return fmt.Errorf("SetTilde: %q must be a JSON object", TildeField)
+	}
+	p.tilde = b
+	return nil
+}
+
+// Tilde unmarshals `~` into dst.  Returns ErrNoTilde if `~` is not set.
+func (p *Pay) Tilde(dst any) error {
+	if p.tilde == nil {
+		return ErrNoTilde
+	}
+	return json.Unmarshal(p.tilde, dst)
+}
+
+// Tilde unmarshals `pay.~` into dst.  With TildeStrict, `pay` is rejected if
+// `~` is not the last field.  See CheckTilde.
+func (cz *Coz) Tilde(dst any, mode TildeMode) error {
+	if mode == TildeStrict {
+		err := CheckTilde(cz.Pay)
+		if err != nil {
+			return err
+		}
+	}
+	p := new(Pay)
+	err := p.unmarshal(cz.Pay, Limits{})
+	if err != nil {
+		return err
+	}
+	return p.Tilde(dst)
+}
+
+// CheckTilde returns ErrTildeNotLast if `~` is present in pay but is not the
+// last field.
+func CheckTilde(pay json.RawMessage) error {
+	can, err := Canon(pay)
+	if err != nil {
+		return err
+	}
+	i := slices.Index(can, TildeField)
+	if i >= 0 && i != len(can)-1 {
+		return ErrTildeNotLast
+	}
+	return nil
+}
+
+// appendTilde appends `~` to the marshaled pay b if set.  typ is the Go type of
+// `Struct` for errors.
+func (p *Pay) appendTilde(b []byte, typ string) ([]byte, error) {
+	if p.tilde == nil {
+		return b, nil
+	}
+	if string(b) != "{}" {
+		b = append(b[:len(b)-1], ',')
+	} else {
+		b = b[:1]
+	}
+	b = append(b, `"~":`...)
+	b = append(append(b, p.tilde...), '}')
+	return b, checkCollision(b, typ)
+}
+
+// tildeValue returns the raw `~` value of the JSON object b, if any.  To avoid
+// a second decode for the common case, b is only decoded if it may contain `~`
+// (directly or escaped).
+func tildeValue(b []byte) (json.RawMessage, error) {
+	if !bytes.Contains(b, []byte(TildeField)) && !bytes.Contains(b, []byte(`\u007`)) {
+		return nil, nil
+	}
+	var t struct {
+		Tilde json.RawMessage `json:"~"`
+	}
+	err := json.Unmarshal(b, &t)
+	if err != nil || t.Tilde == nil {
+		return nil, err
+	}
+	return compact(t.Tilde)
+}
@@ -0,0 +1,99 @@
+package coz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func ExamplePay_SetTilde() {
+	p := &Pay{
+		Alg:    GoldenKey.Alg,
+		Now:    1623132000,
+		Tmb:    GoldenKey.Tmb,
+		Typ:    "cyphr.me/msg/create",
+		Struct: &CustomStruct{Msg: "Coz is a cryptographic JSON messaging specification."},
+	}
+	err := p.SetTilde(map[string]any{"app": "example"})
+	if err != nil {
+		panic(err)
+	}
+	cz, err := GoldenKey.SignPayRaw(p)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(cz.Pay))
+	fmt.Println(GoldenKey.VerifyCoz(cz))
+
+	var app struct {
+		App string `json:"app"`
+	}
+	err = cz.Tilde(&app, TildeStrict)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(app.App)
+
+	// Output:
+	// {"alg":"ES256","now":1623132000,"tmb":"U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg","typ":"cyphr.me/msg/create","msg":"Coz is a cryptographic JSON messaging specification.","~":{"app":"example"}}
+	// true <nil>
+	// example
+}
+
+func TestTilde(t *testing.T) {
+	// Round trip through Unmarshal and Marshal keeps `~` last.
+	in := `{"alg":"ES256","~":{"a": 1},"typ":"x"}`
+	p := new(Pay)
+	err := json.Unmarshal([]byte(in), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"alg":"ES256","typ":"x","~":{"a":1}}` {
+		t.Fatalf("unexpected pay %s", b)
+	}
+
+	// Strict rejects `~` that is not last.
+	cz := &Coz{Pay: json.RawMessage(in)}
+	var v map[string]int
+	if err = cz.Tilde(&v, TildeStrict); !errors.Is(err, ErrTildeNotLast) {
+		t.Fatalf("expected ErrTildeNotLast, given %v", err)
+	}
+	if err = cz.Tilde(&v, TildeLax); err != nil || v["a"] != 1 {
+		t.Fatalf("lax: given %v, %v", v, err)
+	}
+
+	// Escaped field name.
+	if err = json.Unmarshal([]byte(`{"\u007e":{"b":2}}`), p); err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Tilde(&v); err != nil || v["b"] != 2 {
+		t.Fatalf("escaped: given %v, %v", v, err)
+	}
+
+	// Absent, removed, and invalid.
+	if err = new(Pay).Tilde(&v); !errors.Is(err, ErrNoTilde) {
+		t.Fatalf("expected ErrNoTilde, given %v", err)
+	}
+	if err = p.SetTilde(nil); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ = Marshal(p); string(b) != "{}" {
+		t.Fatalf("unexpected pay %s", b)
+	}
+	if err = p.SetTilde("not an object"); err == nil {
+		t.Fatal("expected error for non-object")
+	}
+
+	// Collision with a custom field named `~`.
+	p = &Pay{Struct: map[string]int{TildeField: 1}}
+	p.SetTilde(map[string]int{})
+	var ce *FieldCollisionError
+	if _, err = p.MarshalJSON(); !errors.As(err, &ce) {
+		t.Fatalf("expected *FieldCollisionError, given %v", err)
+	}
+}
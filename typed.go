@@ -0,0 +1,125 @@
+package coz
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// TypedPay is Pay with a typed custom struct.  The fields of T are promoted to
+// top level `pay` fields exactly as Pay.MarshalJSON promotes Pay.Struct.  T
+// must marshal to a JSON object and must not have fields named as standard
+// `pay` fields (alg, now, tmb, typ, rvk), unless owned by T, otherwise a
+// *FieldCollisionError is returned.  See PayFieldOwner.
+type TypedPay[T any] struct {
+	Alg SEAlg     `json:"alg,omitempty"`
+	Now Timestamp `json:"now,omitempty"`
+	Tmb B64       `json:"tmb,omitempty"`
+	Typ string    `json:"typ,omitempty"`
+	Rvk Timestamp `json:"rvk,omitempty"`
+
+	Fields T `json:"-"`
+}
+
+// pay returns p as Pay with Struct set to p.Fields.
+func (p *TypedPay[T]) pay() *Pay {
+	return &Pay{Alg: p.Alg, Now: p.Now, Tmb: p.Tmb, Typ: p.Typ, Rvk: p.Rvk, Struct: &p.Fields}
+}
+
+// String implements fmt.Stringer.  On error, returns the error as a string.
+func (p TypedPay[T]) String() string {
+	b, err := p.MarshalJSON()
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
+
+// MarshalJSON implements json.Marshaler.  See Pay.MarshalJSON.
+func (p *TypedPay[T]) MarshalJSON() ([]byte, error) {
+	return p.pay().MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  See Pay.UnmarshalJSON.
+func (p *TypedPay[T]) UnmarshalJSON(b []byte) error {
+	var fields T
+	err := checkTypeCollision(reflect.TypeFor[T](), ownedFields(&fields))
+	if err != nil {
+		return err
+	}
+	pay := &Pay{Struct: &fields}
+	err = json.Unmarshal(b, pay)
+	if err != nil {
+		return err
+	}
+	*p = TypedPay[T]{Alg: pay.Alg, Now: pay.Now, Tmb: pay.Tmb, Typ: pay.Typ, Rvk: pay.Rvk, Fields: fields}
+	return nil
+}
+
+// TypedCoz is a Coz with `pay` also decoded as TypedPay[T].  Coz.Pay holds the
+// signed `pay` bytes and Typed is decoded from them.
+type TypedCoz[T any] struct {
+	Coz
+	Typed TypedPay[T] `json:"-"`
+}
+
+// MarshalJSON implements json.Marshaler.  If Coz.Pay is empty, `pay` is
+// marshaled from Typed.
+func (cz *TypedCoz[T]) MarshalJSON() (b []byte, err error) {
+	c := cz.Coz
+	if len(c.Pay) == 0 {
+		c.Pay, err = Marshal(&cz.Typed)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return Marshal(&c)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  See Coz.UnmarshalJSON.
+func (cz *TypedCoz[T]) UnmarshalJSON(b []byte) error {
+	c := new(Coz)
+	err := json.Unmarshal(b, c)
+	if err != nil {
+		return err
+	}
+	t := new(TypedPay[T])
+	err = json.Unmarshal(c.Pay, t)
+	if err != nil {
+		return err
+	}
+	cz.Coz, cz.Typed = *c, *t
+	return nil
+}
+
+// SignTyped signs p with key.  Like SignPayRaw, no fields of p are modified.
+// If set, `pay.alg` and `pay.tmb` must match key.
+func SignTyped[T any](key *Key, p *TypedPay[T]) (*TypedCoz[T], error) {
+	b, err := Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	cz, err := key.signPayJSON(&Pay{Alg: p.Alg, Tmb: p.Tmb}, b)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedCoz[T]{Coz: *cz, Typed: *p}, nil
+}
+
+// VerifyTyped unmarshals the coz b and verifies it with key.  See
+// Key.VerifyCoz.
+func VerifyTyped[T any](key *Key, b []byte) (*TypedCoz[T], error) {
+	cz := new(TypedCoz[T])
+	err := json.Unmarshal(b, cz)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := key.VerifyCoz(&cz.Coz)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, errors.New("VerifyTyped: invalid signature")
+	}
+	return cz, nil
+}
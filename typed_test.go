@@ -0,0 +1,123 @@
+package coz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func ExampleSignTyped() {
+	p := &TypedPay[CustomStruct]{
+		Alg:    GoldenKey.Alg,
+		Now:    1623132000,
+		Tmb:    GoldenKey.Tmb,
+		Typ:    "cyphr.me/msg/create",
+		Fields: CustomStruct{Msg: "Coz is a cryptographic JSON messaging specification."},
+	}
+	cz, err := SignTyped(&GoldenKey, p)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(cz.Pay))
+
+	b, err := Marshal(cz)
+	if err != nil {
+		panic(err)
+	}
+	v, err := VerifyTyped[CustomStruct](&GoldenKey, b)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(v.Typed.Fields.Msg)
+
+	// Output:
+	// {"alg":"ES256","now":1623132000,"tmb":"U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg","typ":"cyphr.me/msg/create","msg":"Coz is a cryptographic JSON messaging specification."}
+	// Coz is a cryptographic JSON messaging specification.
+}
+
+func ExampleTypedPay_UnmarshalJSON() {
+	p := new(TypedPay[CustomStruct])
+	err := json.Unmarshal([]byte(GoldenPay), p)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(p.Typ)
+	fmt.Println(p.Fields.Msg)
+
+	// Output:
+	// cyphr.me/msg/create
+	// Coz is a cryptographic JSON messaging specification.
+}
+
+func TestTypedPayCollision(t *testing.T) {
+	type Embedded struct {
+		Alg string `json:"alg,omitempty"`
+	}
+	type withEmbedded struct {
+		Embedded
+		Msg string `json:"msg"`
+	}
+	type untagged struct {
+		Typ string // Unmarshal matches "typ" case insensitively.
+	}
+	type ignored struct {
+		Tmb string `json:"-"`
+		Msg string `json:"msg"`
+	}
+
+	var ce *FieldCollisionError
+	_, err := Marshal(&TypedPay[withEmbedded]{})
+	if !errors.As(err, &ce) || ce.Field != "alg" {
+		t.Fatalf("embedded omitempty: expected collision on alg, given %v", err)
+	}
+	err = json.Unmarshal([]byte(GoldenPay), new(TypedPay[untagged]))
+	if !errors.As(err, &ce) || ce.Field != "Typ" {
+		t.Fatalf("untagged: expected collision on Typ, given %v", err)
+	}
+	_, err = Marshal(&TypedPay[map[string]string]{Tmb: GoldenKey.Tmb, Fields: map[string]string{"tmb": "x"}})
+	if !errors.As(err, &ce) || ce.Field != "tmb" {
+		t.Fatalf("map: expected collision on tmb, given %v", err)
+	}
+
+	b, err := Marshal(&TypedPay[ignored]{Tmb: GoldenKey.Tmb, Fields: ignored{Tmb: "x", Msg: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"tmb":"`+GoldenTmb+`","msg":"m"}` {
+		t.Fatalf("unexpected pay %s", b)
+	}
+
+	// Empty standard fields and empty custom struct.
+	b, err = Marshal(&TypedPay[struct{}]{})
+	if err != nil || string(b) != "{}" {
+		t.Fatalf("empty: given %s, %v", b, err)
+	}
+	b, err = Marshal(&TypedPay[CustomStruct]{Fields: CustomStruct{Msg: "m"}})
+	if err != nil || string(b) != `{"msg":"m"}` {
+		t.Fatalf("contextual: given %s, %v", b, err)
+	}
+}
+
+func TestVerifyTyped(t *testing.T) {
+	cz, err := VerifyTyped[CustomStruct](&GoldenKey, []byte(GoldenCoz))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cz.Typed.Tmb.String() != GoldenTmb || cz.Typed.Fields.Msg == "" {
+		t.Fatalf("unexpected typed pay %s", cz.Typed)
+	}
+
+	// Tampered signature.
+	c := new(Coz)
+	err = json.Unmarshal([]byte(GoldenCoz), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Sig[0] ^= 1
+	b, _ := Marshal(c)
+	_, err = VerifyTyped[CustomStruct](&GoldenKey, b)
+	if err == nil {
+		t.Fatal("expected invalid signature")
+	}
+}
@@ -0,0 +1,75 @@
+package coz
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrUntrustedKey is returned by VerifyEmbedded when the trust callback rejects
+// the embedded key.
+var ErrUntrustedKey = errors.New("Coz: embedded key is not trusted")
+
+// Verbose fills every field of cz for a verbose coz: `key` is set to the public
+// form of key, and `can`, `cad`, and `czd` are calculated with MetaWithAlg.
+// `pay` and `sig` must already be set.  See the README on verbose `coz`.
+func (cz *Coz) Verbose(key *Key) error {
+	if len(cz.Pay) == 0 || len(cz.Sig) == 0 {
+		return errors.New("Verbose: pay and/or sig is empty")
+	}
+	err := cz.MetaWithAlg(key.Alg)
+	if err != nil {
+		return err
+	}
+	cz.Key = key.Public()
+	return nil
+}
+
+// VerifyEmbedded verifies cz using its embedded `key`.  An embedded key proves
+// nothing on its own since anyone may sign with a new key, so trust must
+// report whether the key is trusted, for example by looking up `tmb` in a
+// keyring.
+//
+// VerifyEmbedded errors if:
+//
+//  1. `key` is not embedded, has `prv`, or is not correct.  See Key.Correct.
+//  2. `pay.tmb` is not set or does not match `key.tmb`.
+//  3. Provided `can`, `cad`, or `czd` do not match.  See Check.
+//  4. trust is nil or returns false (ErrUntrustedKey).
+//
+// trust is only called after the checks above, and is given a copy of the key.
+func (cz *Coz) VerifyEmbedded(trust func(*Key) bool) (bool, error) {
+	if cz.Key == nil {
+		return false, errors.New("VerifyEmbedded: key is not embedded")
+	}
+	if len(cz.Key.Prv) != 0 {
+		return false, ErrPrvPresent
+	}
+	k := *cz.Key
+	err := k.Correct()
+	if err != nil {
+		return false, fmt.Errorf("VerifyEmbedded: %w", err)
+	}
+	p := new(Pay)
+	err = p.unmarshal(cz.Pay, Limits{})
+	if err != nil {
+		return false, err
+	}
+	if len(p.Tmb) == 0 {
+		return false, errors.New("VerifyEmbedded: pay.tmb is not set")
+	}
+	if !bytes.Equal(p.Tmb, k.Tmb) {
+		return false, fmt.Errorf("VerifyEmbedded: key tmb %s and pay tmb %s do not match", k.Tmb, p.Tmb)
+	}
+	err = cz.Check()
+	if err != nil {
+		return false, err
+	}
+	if trust == nil {
+		return false, errors.New("VerifyEmbedded: trust is nil")
+	}
+	if !trust(&k) {
+		return false, ErrUntrustedKey
+	}
+	return k.VerifyCoz(cz)
+}
@@ -0,0 +1,85 @@
+package coz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func ExampleCoz_Verbose() {
+	cz := new(Coz)
+	err := json.Unmarshal([]byte(GoldenCoz), cz)
+	if err != nil {
+		panic(err)
+	}
+	err = cz.Verbose(&GoldenKey)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(cz)
+
+	trusted := func(k *Key) bool { return bytes.Equal(k.Tmb, GoldenKey.Tmb) }
+	fmt.Println(cz.VerifyEmbedded(trusted))
+
+	// Output:
+	// {"pay":{"msg":"Coz is a cryptographic JSON messaging specification.","alg":"ES256","now":1623132000,"tmb":"U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg","typ":"cyphr.me/msg/create"},"key":{"alg":"ES256","now":1623132000,"tag":"Zami's Majuscule Key.","tmb":"U5XUZots-WmQYcQWmsO751Xk0yeVi9XUKWQ2mGz6Aqg","pub":"2nTOaFVm2QLxmUO_SjgyscVHBtvHEfo2rq65MvgNRjORojq39Haq9rXNxvXxwba_Xj0F5vZibJR3isBdOWbo5g"},"can":["msg","alg","now","tmb","typ"],"cad":"XzrXMGnY0QFwAKkr43Hh-Ku3yUS8NVE0BdzSlMLSuTU","sig":"OJ4_timgp-wxpLF3hllrbe55wdjhzGOLgRYsGO1BmIMYbo4VKAdgZHnYyIU907ZTJkVr8B81A2K8U4nQA6ONEg","czd":"xrYMu87EXes58PnEACcDW1t0jF2ez4FCN-njTF0MHNo"}
+	// true <nil>
+}
+
+func TestVerifyEmbedded(t *testing.T) {
+	trustAll := func(*Key) bool { return true }
+	verbose := func() *Coz {
+		cz := new(Coz)
+		err := json.Unmarshal([]byte(GoldenCozWKey), cz)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cz.Key = GoldenKey.Public()
+		return cz
+	}
+
+	if v, err := verbose().VerifyEmbedded(trustAll); err != nil || !v {
+		t.Fatalf("expected valid, given %v, %v", v, err)
+	}
+
+	cz := verbose()
+	if _, err := cz.VerifyEmbedded(func(*Key) bool { return false }); !errors.Is(err, ErrUntrustedKey) {
+		t.Fatalf("expected ErrUntrustedKey, given %v", err)
+	}
+	if _, err := cz.VerifyEmbedded(nil); err == nil {
+		t.Fatal("expected error for nil trust")
+	}
+
+	// Trust must not be consulted for a key that does not match.
+	other, err := NewKey(SEAlg(ES256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cz.Key = other.Public()
+	called := false
+	_, err = cz.VerifyEmbedded(func(*Key) bool { called = true; return true })
+	if err == nil || called {
+		t.Fatalf("expected tmb mismatch before trust, given %v (trust called %t)", err, called)
+	}
+
+	cz = verbose()
+	cz.Key = &GoldenKey // Has `prv`.
+	if _, err = cz.VerifyEmbedded(trustAll); !errors.Is(err, ErrPrvPresent) {
+		t.Fatalf("expected ErrPrvPresent, given %v", err)
+	}
+
+	cz = verbose()
+	cz.Czd = MustDecode(GoldenCad)
+	var me *MismatchError
+	if _, err = cz.VerifyEmbedded(trustAll); !errors.As(err, &me) {
+		t.Fatalf("expected *MismatchError, given %v", err)
+	}
+
+	cz = verbose()
+	cz.Key = nil
+	if _, err = cz.VerifyEmbedded(trustAll); err == nil {
+		t.Fatal("expected error for missing key")
+	}
+}